@@ -0,0 +1,91 @@
+package go_http_client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"golang.org/x/sync/singleflight"
+)
+
+type authRefreshTransport struct {
+	wrapped http.RoundTripper
+	token   func(ctx context.Context) (string, error)
+	refresh func(ctx context.Context) error
+	group   *singleflight.Group
+}
+
+func newAuthRefreshTransport(wrapped http.RoundTripper, token func(context.Context) (string, error), refresh func(context.Context) error) http.RoundTripper {
+	return &authRefreshTransport{
+		wrapped: wrapped,
+		token:   token,
+		refresh: refresh,
+		group:   &singleflight.Group{},
+	}
+}
+
+// RoundTrip sends the request with the current bearer token and, if it is
+// rejected with a 401, refreshes the token and sends it once more.
+func (t authRefreshTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Authorization") != "" {
+		return t.wrapped.RoundTrip(req)
+	}
+
+	resp, used, err := t.send(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || t.refresh == nil || !replayable(req) {
+		return resp, err
+	}
+	_ = resp.Body.Close()
+
+	current, err := t.token(req.Context())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get auth token: %w", err)
+	}
+
+	// A token that changed since the request was sent has already been
+	// refreshed, so the request is resent with it. Otherwise concurrent
+	// requests share whichever refresh is in progress.
+	if current == used {
+		if _, err, _ := t.group.Do("refresh", func() (any, error) {
+			return nil, t.refresh(req.Context())
+		}); err != nil {
+			return nil, fmt.Errorf("failed to refresh auth: %w", err)
+		}
+	}
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, _, err = t.send(retry)
+	return resp, err
+}
+
+// send sends a copy of req with the current bearer token, returning the
+// token it used.
+func (t authRefreshTransport) send(req *http.Request) (*http.Response, string, error) {
+	token, err := t.token(req.Context())
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get auth token: %w", err)
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := t.wrapped.RoundTrip(req)
+	return resp, token, err
+}
+
+// replayable reports whether req is idempotent and its body, if any, can be
+// sent again.
+func replayable(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+	default:
+		return false
+	}
+
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
@@ -0,0 +1,158 @@
+package go_http_client_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Auth Refresh", func() {
+	var (
+		server    *httptest.Server
+		valid     atomic.Value
+		requests  atomic.Int32
+		refreshes atomic.Int32
+		current   atomic.Value
+	)
+
+	BeforeEach(func() {
+		requests.Store(0)
+		refreshes.Store(0)
+		valid.Store("Bearer fresh")
+		current.Store("stale")
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			body, _ := io.ReadAll(r.Body)
+			if r.Header.Get("Authorization") != valid.Load().(string) {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write(body)
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	client := func() *http.Client {
+		return baseBuilder().WithAuthRefresh(
+			func(context.Context) (string, error) { return current.Load().(string), nil },
+			func(context.Context) error {
+				refreshes.Add(1)
+				current.Store("fresh")
+				return nil
+			},
+		).Build()
+	}
+
+	It("refreshes the token and resends after a 401", func() {
+		req, err := http.NewRequest(http.MethodPut, server.URL, strings.NewReader("payload"))
+		Expect(err).ToNot(HaveOccurred())
+
+		resp, err := client().Do(req)
+		Expect(err).ToNot(HaveOccurred())
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusOK), "request not resent with the refreshed token")
+		Expect(string(body)).To(Equal("payload"), "body not replayed")
+		Expect(refreshes.Load()).To(BeEquivalentTo(1))
+		Expect(requests.Load()).To(BeEquivalentTo(2))
+	})
+
+	It("refreshes only once and returns a 401 that persists", func() {
+		valid.Store("Bearer never")
+
+		resp, err := client().Get(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Body.Close()).To(Succeed())
+
+		Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+		Expect(refreshes.Load()).To(BeEquivalentTo(1), "refreshed more than once")
+		Expect(requests.Load()).To(BeEquivalentTo(2), "resent more than once")
+	})
+
+	It("returns the 401 without a refresh func", func() {
+		client := baseBuilder().WithAuthRefresh(
+			func(context.Context) (string, error) { return "stale", nil },
+			nil,
+		).Build()
+
+		resp, err := client.Get(server.URL)
+		Expect(err).ToNot(HaveOccurred(), "request without a refresh func failed")
+		Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+		Expect(resp.Body.Close()).To(Succeed())
+		Expect(requests.Load()).To(BeEquivalentTo(1), "request resent without a refresh")
+	})
+
+	It("does not resend non-idempotent requests", func() {
+		resp, err := client().Post(server.URL, "text/plain", strings.NewReader("payload"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Body.Close()).To(Succeed())
+
+		Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+		Expect(refreshes.Load()).To(BeZero())
+		Expect(requests.Load()).To(BeEquivalentTo(1))
+	})
+
+	It("resends without a refresh when the token changed after the request was sent", func() {
+		server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			if r.Header.Get("Authorization") != valid.Load().(string) {
+				// another request refreshed the token while this one was in flight
+				current.Store("fresh")
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		})
+
+		resp, err := client().Get(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Body.Close()).To(Succeed())
+
+		Expect(resp.StatusCode).To(Equal(http.StatusOK), "request not resent with the newer token")
+		Expect(refreshes.Load()).To(BeZero(), "refreshed a token that had already changed")
+		Expect(requests.Load()).To(BeEquivalentTo(2))
+	})
+
+	It("coalesces refreshes across concurrent requests", func() {
+		release := make(chan struct{})
+		client := baseBuilder().WithAuthRefresh(
+			func(context.Context) (string, error) { return current.Load().(string), nil },
+			func(context.Context) error {
+				refreshes.Add(1)
+				<-release
+				current.Store("fresh")
+				return nil
+			},
+		).Build()
+
+		var wg sync.WaitGroup
+		for range 5 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp, err := client.Get(server.URL)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(resp.Body.Close()).To(Succeed())
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			}()
+		}
+
+		Eventually(requests.Load).Should(BeEquivalentTo(5))
+		Consistently(refreshes.Load, "50ms").Should(BeEquivalentTo(1), "concurrent 401s refreshed separately")
+		close(release)
+		wg.Wait()
+
+		Expect(requests.Load()).To(BeEquivalentTo(10), "requests not resent after the shared refresh")
+	})
+})
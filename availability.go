@@ -0,0 +1,94 @@
+package go_http_client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+var ErrHostUnavailable = errors.New("host is unavailable")
+
+// HostUnavailableError is returned for requests to a host marked down,
+// and matches ErrHostUnavailable with errors.Is.
+type HostUnavailableError struct {
+	Host string
+}
+
+func (e *HostUnavailableError) Error() string {
+	return fmt.Sprintf("host %q is unavailable", e.Host)
+}
+
+func (e *HostUnavailableError) Is(target error) bool {
+	return target == ErrHostUnavailable
+}
+
+// HostAvailability fails requests to hosts marked down with a
+// HostUnavailableError without dialing, for when a control plane or
+// operator knows a dependency is down before any breaker does. Hosts are
+// matched with their port first, then without. The zero value is ready to
+// use, with every host available, and can be shared by several clients.
+type HostAvailability struct {
+	mu   sync.RWMutex
+	down map[string]bool
+}
+
+func (a *HostAvailability) SetHostAvailability(host string, available bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if available {
+		delete(a.down, host)
+		return
+	}
+
+	if a.down == nil {
+		a.down = make(map[string]bool)
+	}
+	a.down[host] = true
+}
+
+func (a *HostAvailability) Available(host string) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return !a.down[host]
+}
+
+// unavailableHost returns the entry marked down that matches the URL.
+func (a *HostAvailability) unavailableHost(u *url.URL) (string, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	for _, host := range []string{u.Host, u.Hostname()} {
+		if a.down[host] {
+			return host, true
+		}
+	}
+
+	return "", false
+}
+
+type hostAvailabilityTransport struct {
+	wrapped      http.RoundTripper
+	availability *HostAvailability
+}
+
+func newHostAvailabilityTransport(wrapped http.RoundTripper, availability *HostAvailability) http.RoundTripper {
+	return &hostAvailabilityTransport{
+		wrapped:      wrapped,
+		availability: availability,
+	}
+}
+
+func (t hostAvailabilityTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if host, down := t.availability.unavailableHost(req.URL); down {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+		return nil, &HostUnavailableError{Host: host}
+	}
+
+	return t.wrapped.RoundTrip(req)
+}
@@ -0,0 +1,84 @@
+package go_http_client_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+
+	httpclient "github.com/JSainsburyPLC/danielchurm/go-http-client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HostAvailability", func() {
+	var (
+		server *httptest.Server
+		hits   atomic.Int32
+	)
+
+	BeforeEach(func() {
+		hits.Store(0)
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits.Add(1)
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("fails requests to a host marked down and recovers once it is back", func() {
+		availability := &httpclient.HostAvailability{}
+		client := baseBuilder().WithHostAvailability(availability).Build()
+		host := serverURL(server).Host
+
+		availability.SetHostAvailability(host, false)
+		Expect(availability.Available(host)).To(BeFalse())
+
+		_, err := client.Get(server.URL)
+		Expect(errors.Is(err, httpclient.ErrHostUnavailable)).To(BeTrue(), "request to a down host not rejected")
+
+		var unavailable *httpclient.HostUnavailableError
+		Expect(errors.As(err, &unavailable)).To(BeTrue(), "error is not a HostUnavailableError")
+		Expect(unavailable.Host).To(Equal(host))
+		Expect(hits.Load()).To(BeZero(), "request reached the down host")
+
+		availability.SetHostAvailability(host, true)
+		resp, err := client.Get(server.URL)
+		Expect(err).ToNot(HaveOccurred(), "request rejected after the host came back")
+		Expect(resp.Body.Close()).To(Succeed())
+		Expect(hits.Load()).To(BeEquivalentTo(1))
+	})
+
+	It("matches hosts marked down without a port", func() {
+		availability := &httpclient.HostAvailability{}
+		client := baseBuilder().WithHostAvailability(availability).Build()
+
+		availability.SetHostAvailability(serverURL(server).Hostname(), false)
+		_, err := client.Get(server.URL)
+		Expect(errors.Is(err, httpclient.ErrHostUnavailable)).To(BeTrue(), "hostname entry did not match")
+	})
+
+	It("leaves other hosts available", func() {
+		availability := &httpclient.HostAvailability{}
+		client := baseBuilder().WithHostAvailability(availability).Build()
+
+		availability.SetHostAvailability("elsewhere.example", false)
+		resp, err := client.Get(server.URL)
+		Expect(err).ToNot(HaveOccurred(), "request to an available host rejected")
+		Expect(resp.Body.Close()).To(Succeed())
+	})
+
+	It("sits outside the circuit breaker", func() {
+		chain := httpclient.Default.WithHostAvailability(&httpclient.HostAvailability{}).TransportChain()
+		Expect(chain).To(Equal([]string{"host-availability", "circuitbreaker", "smartshop-headers", "newrelic", "headers", "base"}))
+	})
+})
+
+func serverURL(server *httptest.Server) *url.URL {
+	u, err := url.Parse(server.URL)
+	Expect(err).ToNot(HaveOccurred())
+	return u
+}
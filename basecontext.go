@@ -0,0 +1,83 @@
+package go_http_client
+
+import (
+	"context"
+	"io"
+	"net/http"
+)
+
+// mergedContext is a request context that also carries the values of a base
+// context, preferring the request's own values for the same key.
+type mergedContext struct {
+	context.Context
+	base context.Context
+}
+
+func (c mergedContext) Value(key any) any {
+	if value := c.Context.Value(key); value != nil {
+		return value
+	}
+
+	return c.base.Value(key)
+}
+
+type baseContextTransport struct {
+	wrapped http.RoundTripper
+	base    context.Context
+}
+
+func newBaseContextTransport(wrapped http.RoundTripper, base context.Context) http.RoundTripper {
+	return &baseContextTransport{
+		wrapped: wrapped,
+		base:    base,
+	}
+}
+
+// RoundTrip sends the request with a context that also ends when the base
+// context does, and that lasts until the response body is closed.
+func (t baseContextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithCancelCause(req.Context())
+	stop := context.AfterFunc(t.base, func() {
+		cancel(context.Cause(t.base))
+	})
+	release := func() {
+		stop()
+		cancel(nil)
+	}
+
+	resp, err := t.wrapped.RoundTrip(req.WithContext(mergedContext{Context: ctx, base: t.base}))
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	// A 101 response body is also the connection's writer, so it is wrapped
+	// without hiding its Write method.
+	if conn, ok := resp.Body.(io.ReadWriteCloser); ok && resp.StatusCode == http.StatusSwitchingProtocols {
+		resp.Body = &releasingConn{ReadWriteCloser: conn, release: release}
+		return resp, nil
+	}
+
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	defer b.release()
+	return b.ReadCloser.Close()
+}
+
+type releasingConn struct {
+	io.ReadWriteCloser
+	release func()
+}
+
+func (c *releasingConn) Close() error {
+	defer c.release()
+	return c.ReadWriteCloser.Close()
+}
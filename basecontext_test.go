@@ -0,0 +1,128 @@
+package go_http_client_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	httpclient "github.com/JSainsburyPLC/danielchurm/go-http-client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type tenantKey struct{}
+
+var _ = Describe("Base Context", func() {
+	It("merges the base context values into each request", func() {
+		var seen []any
+		base := context.WithValue(context.Background(), tenantKey{}, "base-tenant")
+		base = httpclient.WithRequestHeaders(base, http.Header{"X-Tenant": {"from-base"}})
+
+		var received string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = r.Header.Get("X-Tenant")
+		}))
+		defer server.Close()
+
+		client := baseBuilder().
+			WithBaseContext(base).
+			WithPreSendHook(func(req *http.Request) { seen = append(seen, req.Context().Value(tenantKey{})) }).
+			Build()
+
+		resp, err := client.Get(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Body.Close()).To(Succeed())
+		Expect(received).To(Equal("from-base"), "base context not visible to the header layer")
+
+		ctx := context.WithValue(context.Background(), tenantKey{}, "request-tenant")
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		Expect(err).ToNot(HaveOccurred())
+		resp, err = client.Do(req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Body.Close()).To(Succeed())
+
+		Expect(seen).To(Equal([]any{"base-tenant", "request-tenant"}), "request values did not take precedence")
+	})
+
+	It("keeps upgraded connections writable", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			conn, rw, err := http.NewResponseController(w).Hijack()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+
+			_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: echo\r\n\r\n")
+			_ = rw.Flush()
+			_, _ = io.Copy(conn, rw)
+		}))
+		defer server.Close()
+
+		// http.Client hides the connection behind its own body for a
+		// client timeout, so upgrades need a client without one.
+		client := baseBuilder().WithTimeout(0).WithBaseContext(context.Background()).Build()
+
+		req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		Expect(err).ToNot(HaveOccurred())
+		req.Header.Set("Connection", "Upgrade")
+		req.Header.Set("Upgrade", "echo")
+
+		resp, err := client.Do(req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusSwitchingProtocols))
+
+		conn, ok := resp.Body.(io.ReadWriteCloser)
+		Expect(ok).To(BeTrue(), "upgraded body is not writable")
+		defer conn.Close()
+
+		_, err = conn.Write([]byte("ping"))
+		Expect(err).ToNot(HaveOccurred())
+
+		echoed := make([]byte, len("ping"))
+		_, err = io.ReadFull(conn, echoed)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(echoed)).To(Equal("ping"))
+	})
+
+	It("aborts requests in flight when the base context is cancelled", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}))
+		defer server.Close()
+
+		base, cancel := context.WithCancel(context.Background())
+		client := baseBuilder().WithBaseContext(base).Build()
+
+		done := make(chan error)
+		go func() {
+			_, err := client.Get(server.URL)
+			done <- err
+		}()
+
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+
+		var err error
+		Eventually(done).Should(Receive(&err))
+		Expect(errors.Is(err, context.Canceled)).To(BeTrue(), "request not aborted by the base context")
+	})
+
+	It("keeps the response body readable until it is closed", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("body"))
+		}))
+		defer server.Close()
+
+		client := baseBuilder().WithBaseContext(context.Background()).Build()
+		resp, err := client.Get(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(body)).To(Equal("body"))
+	})
+})
@@ -0,0 +1,52 @@
+package go_http_client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+var MaxBodyBytes int64 = 10 << 20
+
+var ErrBodyTooLarge = errors.New("response body too large")
+
+// BodyBytes reads and closes the response body, returning at most
+// MaxBodyBytes of it decompressed. Bodies the transport already decompressed
+// are read as is, while gzip bodies it left alone, for example when
+// compression is disabled on the pool, are decompressed here.
+func BodyBytes(resp *http.Response) ([]byte, error) {
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	var body io.Reader = resp.Body
+	if !resp.Uncompressed && strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		gzipReader, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress response body: %w", err)
+		}
+		defer gzipReader.Close()
+
+		body = gzipReader
+	}
+
+	var buf bytes.Buffer
+	if resp.ContentLength > 0 && resp.ContentLength <= MaxBodyBytes && body == resp.Body {
+		buf.Grow(int(resp.ContentLength))
+	}
+
+	n, err := buf.ReadFrom(io.LimitReader(body, MaxBodyBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if n > MaxBodyBytes {
+		return nil, ErrBodyTooLarge
+	}
+
+	return buf.Bytes(), nil
+}
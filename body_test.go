@@ -0,0 +1,80 @@
+package go_http_client_test
+
+import (
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+
+	httpclient "github.com/JSainsburyPLC/danielchurm/go-http-client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Body Bytes", func() {
+	var server *httptest.Server
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Has("plain") {
+				_, _ = w.Write([]byte("hello world"))
+				return
+			}
+
+			w.Header().Set("Content-Encoding", "gzip")
+			gzipWriter := gzip.NewWriter(w)
+			_, _ = gzipWriter.Write([]byte("hello world"))
+			_ = gzipWriter.Close()
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("reads plain responses", func() {
+		resp, err := baseBuilder().Build().Get(server.URL + "?plain")
+		Expect(err).ToNot(HaveOccurred())
+
+		body, err := httpclient.BodyBytes(resp)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(body)).To(Equal("hello world"))
+	})
+
+	It("reads responses the transport decompressed", func() {
+		resp, err := baseBuilder().Build().Get(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Uncompressed).To(BeTrue(), "transport did not decompress")
+		Expect(resp.ContentLength).To(BeEquivalentTo(-1))
+
+		body, err := httpclient.BodyBytes(resp)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(body)).To(Equal("hello world"))
+	})
+
+	It("decompresses responses the transport left compressed", func() {
+		client := baseBuilder().WithPoolSettings(httpclient.PoolSettings{DisableCompression: true}).Build()
+
+		req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		Expect(err).ToNot(HaveOccurred())
+		req.Header.Set("Accept-Encoding", "gzip")
+
+		resp, err := client.Do(req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Uncompressed).To(BeFalse())
+
+		body, err := httpclient.BodyBytes(resp)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(body)).To(Equal("hello world"))
+	})
+
+	It("rejects bodies over the cap", func() {
+		defer func(limit int64) { httpclient.MaxBodyBytes = limit }(httpclient.MaxBodyBytes)
+		httpclient.MaxBodyBytes = 5
+
+		resp, err := baseBuilder().Build().Get(server.URL + "?plain")
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = httpclient.BodyBytes(resp)
+		Expect(err).To(MatchError(httpclient.ErrBodyTooLarge))
+	})
+})
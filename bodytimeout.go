@@ -0,0 +1,86 @@
+package go_http_client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+var ErrResponseBodyTimeout = errors.New("timed out waiting for response body")
+
+type bodyTimeoutTransport struct {
+	wrapped http.RoundTripper
+	timeout time.Duration
+}
+
+func newBodyTimeoutTransport(wrapped http.RoundTripper, timeout time.Duration) http.RoundTripper {
+	return &bodyTimeoutTransport{
+		wrapped: wrapped,
+		timeout: timeout,
+	}
+}
+
+func (t bodyTimeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithCancel(req.Context())
+
+	resp, err := t.wrapped.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	resp.Body = newTimeoutReader(resp.Body, t.timeout, cancel)
+	return resp, nil
+}
+
+// timeoutReader cancels the request when a read waits longer than the
+// timeout for bytes to arrive. The timer only runs while a read is blocked,
+// so time the caller spends between reads is not counted.
+type timeoutReader struct {
+	body     io.ReadCloser
+	timeout  time.Duration
+	timer    *time.Timer
+	cancel   context.CancelFunc
+	timedOut atomic.Bool
+}
+
+func newTimeoutReader(body io.ReadCloser, timeout time.Duration, cancel context.CancelFunc) *timeoutReader {
+	r := &timeoutReader{
+		body:    body,
+		timeout: timeout,
+		cancel:  cancel,
+	}
+
+	r.timer = time.AfterFunc(timeout, func() {
+		r.timedOut.Store(true)
+		cancel()
+	})
+	r.timer.Stop()
+
+	return r
+}
+
+func (r *timeoutReader) Read(p []byte) (int, error) {
+	if r.timedOut.Load() {
+		return 0, ErrResponseBodyTimeout
+	}
+
+	r.timer.Reset(r.timeout)
+	n, err := r.body.Read(p)
+	r.timer.Stop()
+
+	if r.timedOut.Load() {
+		return n, ErrResponseBodyTimeout
+	}
+
+	return n, err
+}
+
+func (r *timeoutReader) Close() error {
+	r.timer.Stop()
+	defer r.cancel()
+	return r.body.Close()
+}
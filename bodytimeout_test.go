@@ -0,0 +1,84 @@
+package go_http_client_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	httpclient "github.com/JSainsburyPLC/danielchurm/go-http-client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Response Body Timeout", func() {
+	newServer := func(chunks int, interval time.Duration) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for range chunks {
+				_, _ = w.Write([]byte("chunk"))
+				w.(http.Flusher).Flush()
+
+				select {
+				case <-r.Context().Done():
+					return
+				case <-time.After(interval):
+				}
+			}
+		}))
+	}
+
+	It("fails reads when the server stalls mid-body", func() {
+		server := newServer(2, time.Second)
+		defer server.Close()
+
+		client := baseBuilder().WithPoolSettings(httpclient.PoolSettings{ResponseBodyTimeout: 50 * time.Millisecond}).Build()
+		resp, err := client.Get(server.URL)
+		Expect(err).ToNot(HaveOccurred(), "response headers should arrive in time")
+		defer resp.Body.Close()
+
+		start := time.Now()
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).To(MatchError(httpclient.ErrResponseBodyTimeout))
+		Expect(string(body)).To(Equal("chunk"), "bytes before the stall not delivered")
+		Expect(time.Since(start)).To(BeNumerically("<", 500*time.Millisecond), "read was not cut short")
+	})
+
+	It("allows slow bodies that keep making progress", func() {
+		server := newServer(5, 10*time.Millisecond)
+		defer server.Close()
+
+		client := baseBuilder().WithPoolSettings(httpclient.PoolSettings{ResponseBodyTimeout: 200 * time.Millisecond}).Build()
+		resp, err := client.Get(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(body)).To(HaveLen(5 * len("chunk")))
+	})
+
+	It("does not count time the caller spends between reads", func() {
+		server := newServer(2, 0)
+		defer server.Close()
+
+		client := baseBuilder().WithPoolSettings(httpclient.PoolSettings{ResponseBodyTimeout: 50 * time.Millisecond}).Build()
+		resp, err := client.Get(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		defer resp.Body.Close()
+
+		time.Sleep(100 * time.Millisecond)
+		chunk := make([]byte, len("chunk"))
+		_, err = io.ReadFull(resp.Body, chunk)
+		Expect(err).ToNot(HaveOccurred(), "waiting before the first read timed out")
+
+		time.Sleep(100 * time.Millisecond)
+		rest, err := io.ReadAll(resp.Body)
+		Expect(err).ToNot(HaveOccurred(), "processing a chunk timed out the next read")
+		Expect(string(rest)).To(Equal("chunk"))
+	})
+
+	It("adds the body timeout to the transport chain", func() {
+		chain := baseBuilder().WithPoolSettings(httpclient.PoolSettings{ResponseBodyTimeout: time.Second}).TransportChain()
+		Expect(chain).To(Equal([]string{"headers", "body-timeout", "base"}))
+	})
+})
@@ -0,0 +1,280 @@
+package go_http_client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+)
+
+var ErrNoCassetteMatch = errors.New("no recorded interaction matches the request")
+
+type CassetteMode int
+
+const (
+	CassetteOff CassetteMode = iota
+	CassetteRecord
+	CassetteReplay
+)
+
+// CassetteMatcher reports whether a recorded request matches the request
+// being replayed.
+type CassetteMatcher func(req RecordedRequest, recorded RecordedRequest) bool
+
+// MatchMethodURLBody is the default CassetteMatcher, matching requests with
+// the same method, URL and body.
+func MatchMethodURLBody(req RecordedRequest, recorded RecordedRequest) bool {
+	return req.Method == recorded.Method && req.URL == recorded.URL && bytes.Equal(req.Body, recorded.Body)
+}
+
+// CassetteSettings configures recording interactions with real upstreams to
+// a file and replaying them offline, for deterministic integration tests.
+type CassetteSettings struct {
+	Mode CassetteMode
+	Path string
+
+	// Matcher picks the recorded interaction to replay for a request,
+	// defaulting to MatchMethodURLBody.
+	Matcher CassetteMatcher
+
+	// RedactHeaders are the request and response headers whose values are
+	// replaced with "REDACTED" in the cassette file, defaulting to
+	// CassetteRedactedHeaders. An empty, non-nil slice records every header
+	// as sent.
+	RedactHeaders []string
+}
+
+// CassetteRedactedHeaders are the credentials redacted from cassette files
+// by default, so recordings can be committed as test fixtures.
+var CassetteRedactedHeaders = []string{"Authorization", "Cookie", "Proxy-Authorization", "Set-Cookie"}
+
+const redactedHeaderValue = "REDACTED"
+
+// redactHeader returns a copy of header with the values of names replaced.
+func redactHeader(header http.Header, names []string) http.Header {
+	header = header.Clone()
+	for _, name := range names {
+		values := header.Values(name)
+		if len(values) == 0 {
+			continue
+		}
+
+		redacted := make([]string, len(values))
+		for i := range redacted {
+			redacted[i] = redactedHeaderValue
+		}
+		header[http.CanonicalHeaderKey(name)] = redacted
+	}
+
+	return header
+}
+
+type cassetteInteraction struct {
+	Request  cassetteRequest  `json:"request"`
+	Response cassetteResponse `json:"response"`
+}
+
+// Bodies are base64 encoded in the file, so that binary bodies survive the
+// round trip through JSON.
+type cassetteRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   []byte      `json:"body,omitempty"`
+}
+
+type cassetteResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       []byte      `json:"body,omitempty"`
+}
+
+func (r cassetteRequest) recorded() RecordedRequest {
+	return RecordedRequest{
+		Method: r.Method,
+		URL:    r.URL,
+		Header: r.Header,
+		Body:   r.Body,
+	}
+}
+
+// cassetteRecordTransport sends requests with the wrapped transport and
+// rewrites the cassette file with every interaction recorded so far.
+type cassetteRecordTransport struct {
+	wrapped      http.RoundTripper
+	path         string
+	redact       []string
+	mu           sync.Mutex
+	interactions []cassetteInteraction
+}
+
+func newCassetteRecordTransport(wrapped http.RoundTripper, path string, redact []string) http.RoundTripper {
+	if redact == nil {
+		redact = CassetteRedactedHeaders
+	}
+
+	return &cassetteRecordTransport{
+		wrapped: wrapped,
+		path:    path,
+		redact:  redact,
+	}
+}
+
+func (t *cassetteRecordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req, reqBody, err := bufferRequestBody(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record request: %w", err)
+	}
+
+	resp, err := t.wrapped.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to record response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	request := snapshotRequest(req, reqBody)
+	if err := t.record(cassetteInteraction{
+		Request: cassetteRequest{
+			Method: request.Method,
+			URL:    request.URL,
+			Header: redactHeader(request.Header, t.redact),
+			Body:   request.Body,
+		},
+		Response: cassetteResponse{
+			StatusCode: resp.StatusCode,
+			Header:     redactHeader(resp.Header, t.redact),
+			Body:       respBody,
+		},
+	}); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (t *cassetteRecordTransport) record(interaction cassetteInteraction) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.interactions = append(t.interactions, interaction)
+
+	data, err := json.MarshalIndent(t.interactions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+
+	if err := os.WriteFile(t.path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+
+	return nil
+}
+
+// cassetteReplayTransport answers requests from the cassette file instead of
+// sending them. Each recorded interaction is replayed once before matching
+// interactions are reused, so repeated identical requests replay in the
+// order they were recorded.
+type cassetteReplayTransport struct {
+	path    string
+	matcher CassetteMatcher
+
+	load         sync.Once
+	loadErr      error
+	mu           sync.Mutex
+	interactions []cassetteInteraction
+	used         []bool
+}
+
+func newCassetteReplayTransport(path string, matcher CassetteMatcher) http.RoundTripper {
+	if matcher == nil {
+		matcher = MatchMethodURLBody
+	}
+
+	return &cassetteReplayTransport{
+		path:    path,
+		matcher: matcher,
+	}
+}
+
+func (t *cassetteReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.load.Do(func() {
+		t.loadErr = t.loadCassette()
+	})
+	if t.loadErr != nil {
+		return nil, t.loadErr
+	}
+
+	req, body, err := bufferRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	interaction, ok := t.match(snapshotRequest(req, body))
+	if !ok {
+		return nil, fmt.Errorf("%w: %s %s", ErrNoCassetteMatch, req.Method, req.URL)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+		StatusCode:    interaction.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        interaction.Response.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(interaction.Response.Body)),
+		ContentLength: int64(len(interaction.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+func (t *cassetteReplayTransport) loadCassette() error {
+	data, err := os.ReadFile(t.path)
+	if err != nil {
+		return fmt.Errorf("failed to read cassette: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &t.interactions); err != nil {
+		return fmt.Errorf("failed to decode cassette: %w", err)
+	}
+
+	t.used = make([]bool, len(t.interactions))
+	return nil
+}
+
+func (t *cassetteReplayTransport) match(req RecordedRequest) (cassetteInteraction, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	found := -1
+	for i, interaction := range t.interactions {
+		if !t.matcher(req, interaction.Request.recorded()) {
+			continue
+		}
+
+		if !t.used[i] {
+			found = i
+			break
+		}
+
+		if found < 0 {
+			found = i
+		}
+	}
+
+	if found < 0 {
+		return cassetteInteraction{}, false
+	}
+
+	t.used[found] = true
+	return t.interactions[found], true
+}
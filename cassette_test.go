@@ -0,0 +1,145 @@
+package go_http_client_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	httpclient "github.com/JSainsburyPLC/danielchurm/go-http-client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cassette", func() {
+	var (
+		server *httptest.Server
+		hits   atomic.Int32
+		path   string
+	)
+
+	BeforeEach(func() {
+		hits.Store(0)
+		path = filepath.Join(GinkgoT().TempDir(), "cassette.json")
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits.Add(1)
+			body, _ := io.ReadAll(r.Body)
+			w.Header().Set("X-Upstream", "real")
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(r.Method + " " + r.URL.Path + " " + string(body)))
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	send := func(client *http.Client, method, path, body string) (*http.Response, string, error) {
+		req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		Expect(err).ToNot(HaveOccurred())
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, "", err
+		}
+		defer resp.Body.Close()
+
+		respBody, err := io.ReadAll(resp.Body)
+		Expect(err).ToNot(HaveOccurred())
+		return resp, string(respBody), nil
+	}
+
+	It("replays recorded interactions without reaching the upstream", func() {
+		recording := baseBuilder().WithRecordCassette(path).Build()
+		_, recorded, err := send(recording, http.MethodPost, "/orders", "one")
+		Expect(err).ToNot(HaveOccurred(), "recording request failed")
+		Expect(recorded).To(Equal("POST /orders one"), "recording changed the response")
+		_, _, err = send(recording, http.MethodPost, "/orders", "two")
+		Expect(err).ToNot(HaveOccurred(), "recording request failed")
+		Expect(hits.Load()).To(BeEquivalentTo(2))
+
+		replaying := baseBuilder().WithReplayCassette(path).Build()
+		resp, body, err := send(replaying, http.MethodPost, "/orders", "two")
+		Expect(err).ToNot(HaveOccurred(), "replay failed")
+		Expect(body).To(Equal("POST /orders two"), "wrong interaction replayed")
+		Expect(resp.StatusCode).To(Equal(http.StatusCreated), "status not replayed")
+		Expect(resp.Header.Get("X-Upstream")).To(Equal("real"), "headers not replayed")
+
+		_, body, err = send(replaying, http.MethodPost, "/orders", "one")
+		Expect(err).ToNot(HaveOccurred(), "replay failed")
+		Expect(body).To(Equal("POST /orders one"), "wrong interaction replayed")
+		Expect(hits.Load()).To(BeEquivalentTo(2), "replay reached the upstream")
+	})
+
+	It("fails requests that were not recorded", func() {
+		_, _, err := send(baseBuilder().WithRecordCassette(path).Build(), http.MethodGet, "/known", "")
+		Expect(err).ToNot(HaveOccurred(), "recording request failed")
+
+		_, _, err = send(baseBuilder().WithReplayCassette(path).Build(), http.MethodGet, "/unknown", "")
+		Expect(errors.Is(err, httpclient.ErrNoCassetteMatch)).To(BeTrue(), "unrecorded request not rejected")
+	})
+
+	It("matches with a custom matcher", func() {
+		_, _, err := send(baseBuilder().WithRecordCassette(path).Build(), http.MethodPost, "/orders", "original")
+		Expect(err).ToNot(HaveOccurred(), "recording request failed")
+
+		replaying := baseBuilder().
+			WithReplayCassette(path).
+			WithCassetteMatcher(func(req, recorded httpclient.RecordedRequest) bool {
+				return req.Method == recorded.Method && req.URL == recorded.URL
+			}).
+			Build()
+
+		_, body, err := send(replaying, http.MethodPost, "/orders", "changed")
+		Expect(err).ToNot(HaveOccurred(), "custom matcher not used")
+		Expect(body).To(Equal("POST /orders original"))
+	})
+
+	It("replaces the base transport when replaying", func() {
+		Expect(baseBuilder().WithReplayCassette(path).TransportChain()).To(Equal([]string{"headers", "cassette-replay"}))
+	})
+
+	It("replays binary bodies unchanged", func() {
+		binary := string([]byte{0x89, 0x50, 0x4e, 0x47, 0xff, 0xfe, 0x00, 0x01})
+
+		recording := baseBuilder().WithRecordCassette(path).Build()
+		_, recorded, err := send(recording, http.MethodPut, "/image", binary)
+		Expect(err).ToNot(HaveOccurred(), "recording request failed")
+
+		replaying := baseBuilder().WithReplayCassette(path).Build()
+		_, replayed, err := send(replaying, http.MethodPut, "/image", binary)
+		Expect(err).ToNot(HaveOccurred(), "binary request did not match on replay")
+		Expect([]byte(replayed)).To(Equal([]byte(recorded)), "binary response body corrupted")
+	})
+
+	It("redacts credentials from the cassette file by default", func() {
+		recording := baseBuilder().WithStaticHeader("Authorization", "Bearer secret-token").WithRecordCassette(path).Build()
+		_, _, err := send(recording, http.MethodGet, "/orders", "")
+		Expect(err).ToNot(HaveOccurred(), "recording request failed")
+
+		data, err := os.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).ToNot(ContainSubstring("secret-token"), "credential written to the cassette")
+		Expect(string(data)).To(ContainSubstring("REDACTED"))
+	})
+
+	It("records headers as configured for redaction", func() {
+		recording := baseBuilder().
+			WithStaticHeader("Authorization", "Bearer test-token").
+			WithStaticHeader("X-Api-Key", "key").
+			WithRecordCassette(path).
+			WithCassetteRedactedHeaders("X-Api-Key").
+			Build()
+		_, _, err := send(recording, http.MethodGet, "/orders", "")
+		Expect(err).ToNot(HaveOccurred(), "recording request failed")
+
+		data, err := os.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring("test-token"), "header redacted though not configured")
+		Expect(string(data)).ToNot(ContainSubstring(`"key"`), "configured header not redacted")
+	})
+})
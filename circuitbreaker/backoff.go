@@ -0,0 +1,65 @@
+package circuitbreaker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sony/gobreaker/v2"
+)
+
+// BackoffStrategy returns how long the breaker stays open after its nth
+// consecutive trip, counting from 1, before it half-opens to probe.
+type BackoffStrategy func(trips int) time.Duration
+
+// ExponentialBackoff doubles the open period on each consecutive trip,
+// starting from initial and never exceeding max.
+func ExponentialBackoff(initial, max time.Duration) BackoffStrategy {
+	return func(trips int) time.Duration {
+		d := initial
+		for i := 1; i < trips && d < max; i++ {
+			d *= 2
+		}
+
+		return min(d, max)
+	}
+}
+
+// halfOpenGate holds a tripped breaker open for the period given by its
+// backoff strategy, growing with each consecutive trip until the breaker
+// closes again.
+type halfOpenGate struct {
+	backoff BackoffStrategy
+
+	mu        sync.Mutex
+	trips     int
+	openUntil time.Time
+}
+
+func (g *halfOpenGate) onStateChange(to gobreaker.State) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	switch to {
+	case gobreaker.StateOpen:
+		g.trips++
+		g.openUntil = time.Now().Add(g.backoff(g.trips))
+	case gobreaker.StateClosed:
+		g.trips = 0
+		g.openUntil = time.Time{}
+	}
+}
+
+func (g *halfOpenGate) open() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return time.Now().Before(g.openUntil)
+}
+
+// retryAfter returns how long the gate stays open for.
+func (g *halfOpenGate) retryAfter() time.Duration {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return max(time.Until(g.openUntil), 0)
+}
@@ -0,0 +1,85 @@
+package circuitbreaker_test
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/JSainsburyPLC/danielchurm/go-http-client/circuitbreaker"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sony/gobreaker/v2"
+)
+
+var _ = Describe("Half Open Backoff", func() {
+	It("waits progressively longer before probing after each trip", func() {
+		unit := 50 * time.Millisecond
+		circuitBreakerRoundTripper := circuitbreaker.NewRoundTripper(
+			&testRoundTripper{StatusCode: http.StatusInternalServerError},
+			circuitbreaker.Settings{
+				Settings: gobreaker.Settings{ReadyToTrip: readyToTrip},
+				HalfOpenBackoff: func(trips int) time.Duration {
+					return time.Duration(trips) * 2 * unit
+				},
+			},
+		)
+
+		// first trip: open for 2 units
+		_, err := circuitBreakerRoundTripper.RoundTrip(nil)
+		Expect(err).ToNot(HaveOccurred(), "error returned on the first call")
+
+		_, err = circuitBreakerRoundTripper.RoundTrip(nil)
+		Expect(err).To(MatchError(gobreaker.ErrOpenState), "did not enter open state")
+
+		time.Sleep(3 * unit)
+		_, err = circuitBreakerRoundTripper.RoundTrip(nil)
+		Expect(err).ToNot(HaveOccurred(), "did not probe after the first backoff")
+
+		// second trip: open for 4 units
+		time.Sleep(3 * unit)
+		_, err = circuitBreakerRoundTripper.RoundTrip(nil)
+		Expect(err).To(MatchError(gobreaker.ErrOpenState), "probed before the second, longer backoff")
+
+		time.Sleep(2 * unit)
+		_, err = circuitBreakerRoundTripper.RoundTrip(nil)
+		Expect(err).ToNot(HaveOccurred(), "did not probe after the second backoff")
+	})
+
+	It("resets the backoff once the breaker closes", func() {
+		unit := 50 * time.Millisecond
+		statusCode := http.StatusInternalServerError
+		circuitBreakerRoundTripper := circuitbreaker.NewRoundTripper(
+			roundTripperFunc(func(*http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: statusCode}, nil
+			}),
+			circuitbreaker.Settings{
+				Settings: gobreaker.Settings{ReadyToTrip: readyToTrip},
+				HalfOpenBackoff: func(trips int) time.Duration {
+					return time.Duration(trips) * 2 * unit
+				},
+			},
+		)
+
+		_, _ = circuitBreakerRoundTripper.RoundTrip(nil)
+		time.Sleep(3 * unit)
+
+		statusCode = http.StatusOK
+		_, err := circuitBreakerRoundTripper.RoundTrip(nil)
+		Expect(err).ToNot(HaveOccurred(), "successful probe failed")
+
+		statusCode = http.StatusInternalServerError
+		_, _ = circuitBreakerRoundTripper.RoundTrip(nil)
+		time.Sleep(3 * unit)
+
+		_, err = circuitBreakerRoundTripper.RoundTrip(nil)
+		Expect(err).ToNot(HaveOccurred(), "backoff not reset after closing")
+	})
+
+	It("grows exponentially up to the maximum", func() {
+		backoff := circuitbreaker.ExponentialBackoff(time.Second, 5*time.Second)
+		Expect(backoff(1)).To(Equal(time.Second))
+		Expect(backoff(2)).To(Equal(2 * time.Second))
+		Expect(backoff(3)).To(Equal(4 * time.Second))
+		Expect(backoff(4)).To(Equal(5 * time.Second))
+		Expect(backoff(100)).To(Equal(5 * time.Second))
+	})
+})
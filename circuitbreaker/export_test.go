@@ -0,0 +1,11 @@
+package circuitbreaker
+
+import "github.com/sony/gobreaker/v2"
+
+// SetStateChangeLogger replaces the default state change logging until the
+// returned func restores it.
+func SetStateChangeLogger(fn func(name string, from gobreaker.State, to gobreaker.State)) (restore func()) {
+	previous := logStateChange
+	logStateChange = fn
+	return func() { logStateChange = previous }
+}
@@ -2,7 +2,9 @@ package circuitbreaker
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"time"
 
 	log "github.com/JSainsburyPLC/go-logrus-wrapper/v2"
 	"github.com/sirupsen/logrus"
@@ -10,20 +12,160 @@ import (
 	"github.com/sony/gobreaker/v2"
 )
 
+// Settings configures the circuit breaker transport. Any error returned by
+// the wrapped transport, such as a failed DNS lookup or dial, counts as a
+// failure, as does any response for which ShouldTrip returns true.
+//
+// Every state change is logged with LogStateChange before OnStateChange is
+// called, unless DisableDefaultStateLogging is set.
 type Settings struct {
 	gobreaker.Settings
 	ShouldTrip func(statusCode int) bool
+
+	DisableDefaultStateLogging bool
+
+	// SlowCallThreshold, when set, counts calls that take longer than it
+	// as failures even if the response is successful.
+	SlowCallThreshold time.Duration
+
+	// SlowCallRateToTrip, when set and ReadyToTrip is nil, trips the
+	// breaker once the share of failed calls (slow or otherwise) in the
+	// current interval reaches this rate, between 0 and 1.
+	SlowCallRateToTrip float64
+
+	// SlowCallMinimumRequests is how many calls the current interval must
+	// have seen before SlowCallRateToTrip is checked, so that a single early
+	// failure does not trip the breaker. It defaults to 10.
+	SlowCallMinimumRequests uint32
+
+	// FailureWeight, when set, is how many failures a response that
+	// ShouldTrip matched counts as. The breaker only counts failures one at
+	// a time, so a weight of n records the failure n times, which also adds
+	// n requests to the breaker's counts. A weight of 0 or less counts no
+	// failures, and the response is recorded as a success.
+	FailureWeight func(statusCode int) int
+
+	// Registry, when set, shares the breaker with every other transport
+	// using the same registry and Name. The breaker is created from the
+	// gobreaker settings of the first transport to use the name.
+	Registry *Registry
+
+	// IsSuccessful, when set, reports whether a call counts as a success
+	// from its response and error, so that expected failures such as a 404
+	// modelled as an error do not trip the breaker. It takes precedence over
+	// ShouldTrip, SlowCallThreshold and the IsSuccessful of the embedded
+	// gobreaker settings, and errors it accepts are still returned.
+	IsSuccessful func(resp *http.Response, err error) bool
+
+	// HalfOpenBackoff, when set, replaces the fixed Timeout the breaker
+	// stays open for with one that grows on each consecutive trip, so a
+	// service that stays down is probed less and less often.
+	HalfOpenBackoff BackoffStrategy
+}
+
+// defaultTimeout is how long gobreaker stays open for when Timeout is unset.
+const defaultTimeout = 60 * time.Second
+
+// defaultSlowCallMinimumRequests is how many calls SlowCallRateToTrip waits
+// for when SlowCallMinimumRequests is unset.
+const defaultSlowCallMinimumRequests = 10
+
+type breaker struct {
+	name    string
+	cb      *gobreaker.CircuitBreaker[*http.Response]
+	gate    *halfOpenGate
+	metrics *stateMetrics
+	timeout time.Duration
+}
+
+func newBreaker(settings Settings) *breaker {
+	timeout := settings.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	metrics := newStateMetrics()
+	onStateChange := settings.OnStateChange
+	settings.OnStateChange = func(name string, from gobreaker.State, to gobreaker.State) {
+		metrics.onStateChange(to)
+		onStateChange(name, from, to)
+	}
+
+	var gate *halfOpenGate
+	if settings.HalfOpenBackoff != nil {
+		gate = &halfOpenGate{backoff: settings.HalfOpenBackoff}
+
+		onStateChange := settings.OnStateChange
+		settings.OnStateChange = func(name string, from gobreaker.State, to gobreaker.State) {
+			gate.onStateChange(to)
+			onStateChange(name, from, to)
+		}
+
+		// The gate holds the breaker open, so let it half-open as soon as
+		// the gate lets a request through.
+		settings.Timeout = time.Nanosecond
+	}
+
+	return &breaker{
+		name:    settings.Name,
+		cb:      gobreaker.NewCircuitBreaker[*http.Response](settings.Settings),
+		gate:    gate,
+		metrics: metrics,
+		timeout: timeout,
+	}
+}
+
+// openError returns the error for a request rejected while the breaker is
+// open, estimating when it will next let a request through.
+func (b *breaker) openError() error {
+	var retryAfter time.Duration
+	if b.gate != nil {
+		retryAfter = b.gate.retryAfter()
+	} else if openFor, ok := b.metrics.timeInOpenState(); ok {
+		retryAfter = max(b.timeout-openFor, 0)
+	}
+
+	return &BreakerOpenError{Key: b.name, RetryAfter: retryAfter}
+}
+
+// BreakerOpenError is returned for requests rejected while a breaker is
+// open, and matches gobreaker.ErrOpenState with errors.Is. RetryAfter
+// estimates how long until the breaker half-opens to let a probe through,
+// for callers implementing backoff.
+type BreakerOpenError struct {
+	Key        string
+	RetryAfter time.Duration
+}
+
+func (e *BreakerOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker %q is open, retry after %s", e.Key, e.RetryAfter)
+}
+
+func (e *BreakerOpenError) Unwrap() error {
+	return gobreaker.ErrOpenState
 }
 
 type circuitBreakerTransport struct {
-	wrapped    http.RoundTripper
-	cb         *gobreaker.CircuitBreaker[*http.Response]
-	shouldTrip func(statusCode int) bool
+	wrapped           http.RoundTripper
+	breaker           *breaker
+	cb                *gobreaker.CircuitBreaker[*http.Response]
+	gate              *halfOpenGate
+	shouldTrip        func(statusCode int) bool
+	isSuccessful      func(resp *http.Response, err error) bool
+	slowCallThreshold time.Duration
+	failureWeight     func(statusCode int) int
 }
 
 func NewRoundTripper(wrapped http.RoundTripper, settings Settings) http.RoundTripper {
-	if settings.OnStateChange == nil {
-		settings.OnStateChange = logCBStateChange
+	onStateChange := settings.OnStateChange
+	settings.OnStateChange = func(name string, from gobreaker.State, to gobreaker.State) {
+		if !settings.DisableDefaultStateLogging {
+			logStateChange(name, from, to)
+		}
+
+		if onStateChange != nil {
+			onStateChange(name, from, to)
+		}
 	}
 
 	if settings.ShouldTrip == nil {
@@ -32,36 +174,153 @@ func NewRoundTripper(wrapped http.RoundTripper, settings Settings) http.RoundTri
 		}
 	}
 
+	if settings.ReadyToTrip == nil && settings.SlowCallRateToTrip > 0 {
+		minimumRequests := settings.SlowCallMinimumRequests
+		if minimumRequests == 0 {
+			minimumRequests = defaultSlowCallMinimumRequests
+		}
+
+		settings.ReadyToTrip = func(counts gobreaker.Counts) bool {
+			return counts.Requests >= minimumRequests &&
+				float64(counts.TotalFailures)/float64(counts.Requests) >= settings.SlowCallRateToTrip
+		}
+	}
+
+	if settings.IsSuccessful != nil {
+		isSuccessful := settings.Settings.IsSuccessful
+		settings.Settings.IsSuccessful = func(err error) bool {
+			var successful *successfulError
+			if errors.As(err, &successful) {
+				return true
+			}
+
+			if isSuccessful != nil {
+				return isSuccessful(err)
+			}
+
+			return err == nil
+		}
+	}
+
+	var b *breaker
+	if settings.Registry != nil {
+		b = settings.Registry.get(settings)
+	} else {
+		b = newBreaker(settings)
+	}
+
 	return &circuitBreakerTransport{
-		wrapped:    wrapped,
-		cb:         gobreaker.NewCircuitBreaker[*http.Response](settings.Settings),
-		shouldTrip: settings.ShouldTrip,
+		wrapped:           wrapped,
+		breaker:           b,
+		cb:                b.cb,
+		gate:              b.gate,
+		shouldTrip:        settings.ShouldTrip,
+		isSuccessful:      settings.IsSuccessful,
+		slowCallThreshold: settings.SlowCallThreshold,
+		failureWeight:     settings.FailureWeight,
 	}
 }
 
-var errBadResponse = errors.New("server error")
+var (
+	errBadResponse = errors.New("server error")
+	errSlowCall    = errors.New("slow call")
+)
+
+// successfulError carries an error that IsSuccessful accepted through the
+// breaker without it counting as a failure.
+type successfulError struct {
+	err error
+}
+
+func (e *successfulError) Error() string { return e.err.Error() }
 
 func (t circuitBreakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.gate != nil && t.gate.open() {
+		return nil, t.breaker.openError()
+	}
+
+	weight := 1
 	resp, err := t.cb.Execute(func() (*http.Response, error) {
+		start := time.Now()
 		resp, err := t.wrapped.RoundTrip(req)
+		if t.isSuccessful != nil {
+			successful := t.isSuccessful(resp, err)
+			switch {
+			case successful && err != nil:
+				return resp, &successfulError{err: err}
+			case successful:
+				return resp, nil
+			case err != nil:
+				return resp, err
+			default:
+				return t.badResponse(resp, &weight)
+			}
+		}
+
 		if resp != nil && t.shouldTrip(resp.StatusCode) {
-			return resp, errBadResponse
+			return t.badResponse(resp, &weight)
+		}
+
+		if err == nil && t.slowCallThreshold > 0 && time.Since(start) > t.slowCallThreshold {
+			return resp, errSlowCall
 		}
 
 		return resp, err
 	})
 
 	// If the server returns an error, suppress and let the HTTP client caller
-	// decide how to handle the response body. This error is used internally
-	// to force the circuit breaker to trip when the server returns a 5XX response.
+	// decide how to handle the response body. These errors are used internally
+	// to force the circuit breaker to trip when the server returns a 5XX
+	// response or responds too slowly.
 	if errors.Is(err, errBadResponse) {
+		t.recordExtraFailures(weight)
 		return resp, nil
 	}
 
+	if errors.Is(err, errSlowCall) {
+		return resp, nil
+	}
+
+	var successful *successfulError
+	if errors.As(err, &successful) {
+		return resp, successful.err
+	}
+
+	if errors.Is(err, gobreaker.ErrOpenState) {
+		return nil, t.breaker.openError()
+	}
+
 	return resp, err
 }
 
-func logCBStateChange(name string, from gobreaker.State, to gobreaker.State) {
+// badResponse counts resp as a failure, storing its weight for
+// recordExtraFailures, unless its weight is 0 or less.
+func (t circuitBreakerTransport) badResponse(resp *http.Response, weight *int) (*http.Response, error) {
+	if t.failureWeight != nil {
+		*weight = t.failureWeight(resp.StatusCode)
+	}
+
+	if *weight <= 0 {
+		return resp, nil
+	}
+
+	return resp, errBadResponse
+}
+
+func (t circuitBreakerTransport) recordExtraFailures(weight int) {
+	for range weight - 1 {
+		_, _ = t.cb.Execute(func() (*http.Response, error) {
+			return nil, errBadResponse
+		})
+	}
+}
+
+// logStateChange is how state changes are logged by default.
+var logStateChange = LogStateChange
+
+// LogStateChange logs a state transition as an error, and is how every
+// breaker logs its state changes by default.
+func LogStateChange(name string, from gobreaker.State, to gobreaker.State) {
 	log.WithFields(logrus.Fields{
 		"circuit_breaker": name,
 		"from_state":      from.String(),
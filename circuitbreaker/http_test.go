@@ -1,10 +1,13 @@
 package circuitbreaker_test
 
 import (
+	"context"
 	"errors"
+	"net"
 	"net/http"
+	"time"
 
-	"github.com/JSainsburyPLC/smartshop-api-shopper-orchestrator/circuitbreaker"
+	"github.com/JSainsburyPLC/danielchurm/go-http-client/circuitbreaker"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/sony/gobreaker/v2"
@@ -69,6 +72,29 @@ var _ = Describe("Circuit Breaker", func() {
 		Expect(err).To(MatchError(gobreaker.ErrOpenState), "did not enter open state on second call")
 	})
 
+	It("trips on connection failures and fails fast while open", func() {
+		dials := 0
+		circuitBreakerRoundTripper := circuitbreaker.NewRoundTripper(
+			&http.Transport{DialContext: func(context.Context, string, string) (net.Conn, error) {
+				dials++
+				return nil, errors.New("connection refused")
+			}},
+			circuitbreaker.Settings{
+				Settings: gobreaker.Settings{ReadyToTrip: readyToTrip},
+			},
+		)
+
+		req, err := http.NewRequest(http.MethodGet, "http://unreachable.invalid/", nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = circuitBreakerRoundTripper.RoundTrip(req)
+		Expect(err).To(MatchError(ContainSubstring("connection refused")), "dial error not returned")
+
+		_, err = circuitBreakerRoundTripper.RoundTrip(req)
+		Expect(err).To(MatchError(gobreaker.ErrOpenState), "did not enter open state on second call")
+		Expect(dials).To(Equal(1), "dialled while the breaker was open")
+	})
+
 	It("trips after multiple consecutive failures", func() {
 		consecutiveFailuresAllowed := 3
 		circuitBreakerRoundTripper := circuitbreaker.NewRoundTripper(
@@ -89,19 +115,308 @@ var _ = Describe("Circuit Breaker", func() {
 		_, err := circuitBreakerRoundTripper.RoundTrip(nil)
 		Expect(err).To(MatchError(gobreaker.ErrOpenState), "did not enter open state on second call")
 	})
+
+	It("trips on slow calls even when the response is successful", func() {
+		circuitBreakerRoundTripper := circuitbreaker.NewRoundTripper(
+			&testRoundTripper{StatusCode: http.StatusOK, Delay: 20 * time.Millisecond},
+			circuitbreaker.Settings{
+				Settings:          gobreaker.Settings{ReadyToTrip: readyToTrip},
+				SlowCallThreshold: 10 * time.Millisecond,
+			},
+		)
+
+		resp, err := circuitBreakerRoundTripper.RoundTrip(nil)
+		Expect(err).ToNot(HaveOccurred(), "error returned on the first call")
+		Expect(resp).ToNot(BeNil(), "no response returned")
+
+		_, err = circuitBreakerRoundTripper.RoundTrip(nil)
+		Expect(err).To(MatchError(gobreaker.ErrOpenState), "did not enter open state on second call")
+	})
+
+	It("does not count calls under the slow call threshold", func() {
+		circuitBreakerRoundTripper := circuitbreaker.NewRoundTripper(
+			&testRoundTripper{StatusCode: http.StatusOK},
+			circuitbreaker.Settings{
+				Settings:          gobreaker.Settings{ReadyToTrip: readyToTrip},
+				SlowCallThreshold: time.Second,
+			},
+		)
+
+		for range 3 {
+			_, err := circuitBreakerRoundTripper.RoundTrip(nil)
+			Expect(err).ToNot(HaveOccurred(), "circuitbreaker should not have been tripped")
+		}
+	})
+
+	It("trips once the slow call rate is reached", func() {
+		calls := 0
+		circuitBreakerRoundTripper := circuitbreaker.NewRoundTripper(
+			roundTripperFunc(func(*http.Request) (*http.Response, error) {
+				calls++
+				if calls%2 == 0 {
+					time.Sleep(20 * time.Millisecond)
+				}
+				return &http.Response{StatusCode: http.StatusOK}, nil
+			}),
+			circuitbreaker.Settings{
+				SlowCallThreshold:       10 * time.Millisecond,
+				SlowCallRateToTrip:      0.5,
+				SlowCallMinimumRequests: 2,
+			},
+		)
+
+		for range 2 {
+			_, err := circuitBreakerRoundTripper.RoundTrip(nil)
+			Expect(err).ToNot(HaveOccurred(), "error returned before the slow call rate was reached")
+		}
+
+		_, err := circuitBreakerRoundTripper.RoundTrip(nil)
+		Expect(err).To(MatchError(gobreaker.ErrOpenState), "did not enter open state once half the calls were slow")
+	})
+
+	It("waits for the minimum number of calls before checking the slow call rate", func() {
+		calls := 0
+		circuitBreakerRoundTripper := circuitbreaker.NewRoundTripper(
+			roundTripperFunc(func(*http.Request) (*http.Response, error) {
+				calls++
+				if calls == 1 {
+					time.Sleep(20 * time.Millisecond)
+				}
+				return &http.Response{StatusCode: http.StatusOK}, nil
+			}),
+			circuitbreaker.Settings{
+				SlowCallThreshold:  10 * time.Millisecond,
+				SlowCallRateToTrip: 0.5,
+			},
+		)
+
+		for range 5 {
+			_, err := circuitBreakerRoundTripper.RoundTrip(nil)
+			Expect(err).ToNot(HaveOccurred(), "a single slow call tripped the breaker")
+		}
+	})
+
+	It("trips faster on heavily weighted failures", func() {
+		settings := circuitbreaker.Settings{
+			Settings: gobreaker.Settings{ReadyToTrip: func(counts gobreaker.Counts) bool {
+				return counts.ConsecutiveFailures >= 3
+			}},
+			FailureWeight: func(statusCode int) int {
+				if statusCode == http.StatusServiceUnavailable {
+					return 3
+				}
+				return 1
+			},
+		}
+
+		heavy := circuitbreaker.NewRoundTripper(&testRoundTripper{StatusCode: http.StatusServiceUnavailable}, settings)
+		_, err := heavy.RoundTrip(nil)
+		Expect(err).ToNot(HaveOccurred(), "error returned on the first call")
+
+		_, err = heavy.RoundTrip(nil)
+		Expect(err).To(MatchError(gobreaker.ErrOpenState), "heavily weighted failure did not trip on the first call")
+
+		light := circuitbreaker.NewRoundTripper(&testRoundTripper{StatusCode: http.StatusInternalServerError}, settings)
+		for range 3 {
+			_, err := light.RoundTrip(nil)
+			Expect(err).ToNot(HaveOccurred(), "lightly weighted failure tripped early")
+		}
+
+		_, err = light.RoundTrip(nil)
+		Expect(err).To(MatchError(gobreaker.ErrOpenState), "did not enter open state after three failures")
+	})
+	It("does not count failures with a weight of zero", func() {
+		circuitBreakerRoundTripper := circuitbreaker.NewRoundTripper(
+			&testRoundTripper{StatusCode: http.StatusServiceUnavailable},
+			circuitbreaker.Settings{
+				Settings:      gobreaker.Settings{ReadyToTrip: func(counts gobreaker.Counts) bool { return counts.TotalFailures > 0 }},
+				FailureWeight: func(int) int { return 0 },
+			},
+		)
+
+		for range 3 {
+			resp, err := circuitBreakerRoundTripper.RoundTrip(nil)
+			Expect(err).ToNot(HaveOccurred(), "zero weighted failure tripped the breaker")
+			Expect(resp.StatusCode).To(Equal(http.StatusServiceUnavailable))
+		}
+	})
+
+	It("returns a typed error estimating when the breaker will half-open", func() {
+		circuitBreakerRoundTripper := circuitbreaker.NewRoundTripper(
+			&testRoundTripper{StatusCode: http.StatusInternalServerError},
+			circuitbreaker.Settings{
+				Settings: gobreaker.Settings{Name: "upstream", ReadyToTrip: readyToTrip, Timeout: 10 * time.Second},
+			},
+		)
+
+		_, err := circuitBreakerRoundTripper.RoundTrip(nil)
+		Expect(err).ToNot(HaveOccurred(), "error returned on the first call")
+
+		time.Sleep(20 * time.Millisecond)
+		_, err = circuitBreakerRoundTripper.RoundTrip(nil)
+		Expect(errors.Is(err, gobreaker.ErrOpenState)).To(BeTrue(), "typed error does not match ErrOpenState")
+
+		var openErr *circuitbreaker.BreakerOpenError
+		Expect(errors.As(err, &openErr)).To(BeTrue(), "error is not a BreakerOpenError")
+		Expect(openErr.Key).To(Equal("upstream"))
+		Expect(openErr.RetryAfter).To(BeNumerically("<=", 10*time.Second-20*time.Millisecond), "retry after ignores time already spent open")
+		Expect(openErr.RetryAfter).To(BeNumerically(">", 9*time.Second), "retry after not derived from the timeout")
+	})
+
+	It("estimates the retry after from the half-open backoff", func() {
+		circuitBreakerRoundTripper := circuitbreaker.NewRoundTripper(
+			&testRoundTripper{StatusCode: http.StatusInternalServerError},
+			circuitbreaker.Settings{
+				Settings:        gobreaker.Settings{ReadyToTrip: readyToTrip},
+				HalfOpenBackoff: circuitbreaker.ExponentialBackoff(time.Minute, time.Hour),
+			},
+		)
+
+		_, err := circuitBreakerRoundTripper.RoundTrip(nil)
+		Expect(err).ToNot(HaveOccurred(), "error returned on the first call")
+
+		_, err = circuitBreakerRoundTripper.RoundTrip(nil)
+		var openErr *circuitbreaker.BreakerOpenError
+		Expect(errors.As(err, &openErr)).To(BeTrue(), "error is not a BreakerOpenError")
+		Expect(openErr.RetryAfter).To(BeNumerically("~", time.Minute, time.Second))
+	})
+	It("does not trip on responses IsSuccessful accepts", func() {
+		circuitBreakerRoundTripper := circuitbreaker.NewRoundTripper(
+			&testRoundTripper{StatusCode: http.StatusNotFound},
+			circuitbreaker.Settings{
+				Settings:   gobreaker.Settings{ReadyToTrip: readyToTrip},
+				ShouldTrip: func(statusCode int) bool { return statusCode >= 400 },
+				IsSuccessful: func(resp *http.Response, err error) bool {
+					return resp != nil && resp.StatusCode == http.StatusNotFound
+				},
+			},
+		)
+
+		for range 3 {
+			resp, err := circuitBreakerRoundTripper.RoundTrip(nil)
+			Expect(err).ToNot(HaveOccurred(), "accepted 404 tripped the breaker")
+			Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		}
+	})
+
+	It("returns errors IsSuccessful accepts without tripping", func() {
+		errNotFound := errors.New("not found")
+		circuitBreakerRoundTripper := circuitbreaker.NewRoundTripper(
+			&testRoundTripper{Error: errNotFound},
+			circuitbreaker.Settings{
+				Settings: gobreaker.Settings{ReadyToTrip: readyToTrip},
+				IsSuccessful: func(resp *http.Response, err error) bool {
+					return errors.Is(err, errNotFound)
+				},
+			},
+		)
+
+		for range 3 {
+			_, err := circuitBreakerRoundTripper.RoundTrip(nil)
+			Expect(err).To(MatchError(errNotFound), "accepted error not returned as is")
+		}
+	})
+
+	It("still trips on failures IsSuccessful rejects", func() {
+		circuitBreakerRoundTripper := circuitbreaker.NewRoundTripper(
+			&testRoundTripper{StatusCode: http.StatusInternalServerError},
+			circuitbreaker.Settings{
+				Settings: gobreaker.Settings{ReadyToTrip: readyToTrip},
+				IsSuccessful: func(resp *http.Response, err error) bool {
+					return err == nil && resp.StatusCode < 500
+				},
+			},
+		)
+
+		_, err := circuitBreakerRoundTripper.RoundTrip(nil)
+		Expect(err).ToNot(HaveOccurred(), "error returned on the first call")
+
+		_, err = circuitBreakerRoundTripper.RoundTrip(nil)
+		Expect(err).To(MatchError(gobreaker.ErrOpenState), "rejected failure did not trip the breaker")
+	})
+
+	It("trips on responses IsSuccessful rejects even when ShouldTrip would not", func() {
+		circuitBreakerRoundTripper := circuitbreaker.NewRoundTripper(
+			&testRoundTripper{StatusCode: http.StatusNotFound},
+			circuitbreaker.Settings{
+				Settings: gobreaker.Settings{ReadyToTrip: readyToTrip},
+				IsSuccessful: func(resp *http.Response, err error) bool {
+					return err == nil && resp.StatusCode != http.StatusNotFound
+				},
+			},
+		)
+
+		resp, err := circuitBreakerRoundTripper.RoundTrip(nil)
+		Expect(err).ToNot(HaveOccurred(), "error returned on the first call")
+		Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+
+		_, err = circuitBreakerRoundTripper.RoundTrip(nil)
+		Expect(err).To(MatchError(gobreaker.ErrOpenState), "rejected 404 did not trip the breaker")
+	})
+
+	It("logs state changes by default before calling OnStateChange", func() {
+		var calls []string
+		DeferCleanup(circuitbreaker.SetStateChangeLogger(func(name string, from gobreaker.State, to gobreaker.State) {
+			calls = append(calls, "default "+to.String())
+		}))
+
+		circuitBreakerRoundTripper := circuitbreaker.NewRoundTripper(
+			&testRoundTripper{StatusCode: http.StatusInternalServerError},
+			circuitbreaker.Settings{
+				Settings: gobreaker.Settings{
+					ReadyToTrip: readyToTrip,
+					OnStateChange: func(name string, from gobreaker.State, to gobreaker.State) {
+						calls = append(calls, "user "+to.String())
+					},
+				},
+			},
+		)
+
+		_, _ = circuitBreakerRoundTripper.RoundTrip(nil)
+		Expect(calls).To(Equal([]string{"default open", "user open"}), "state change hooks not both called in order")
+	})
+
+	It("skips the default state logging when disabled", func() {
+		var calls []string
+		DeferCleanup(circuitbreaker.SetStateChangeLogger(func(name string, from gobreaker.State, to gobreaker.State) {
+			calls = append(calls, "default "+to.String())
+		}))
+
+		circuitBreakerRoundTripper := circuitbreaker.NewRoundTripper(
+			&testRoundTripper{StatusCode: http.StatusInternalServerError},
+			circuitbreaker.Settings{
+				Settings: gobreaker.Settings{
+					ReadyToTrip: readyToTrip,
+					OnStateChange: func(name string, from gobreaker.State, to gobreaker.State) {
+						calls = append(calls, "user "+to.String())
+					},
+				},
+				DisableDefaultStateLogging: true,
+			},
+		)
+
+		_, _ = circuitBreakerRoundTripper.RoundTrip(nil)
+		Expect(calls).To(Equal([]string{"user open"}), "default state logging not disabled")
+	})
 })
 
 type testRoundTripper struct {
 	StatusCode int
 	Error      error
+	Delay      time.Duration
 }
 
 func (rt testRoundTripper) RoundTrip(*http.Request) (*http.Response, error) {
+	time.Sleep(rt.Delay)
 	if rt.Error != nil {
 		return nil, rt.Error
 	}
 	return &http.Response{StatusCode: rt.StatusCode}, nil
 }
 
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
 // enter open state after 1 error
 func readyToTrip(gobreaker.Counts) bool { return true }
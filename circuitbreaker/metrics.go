@@ -0,0 +1,78 @@
+package circuitbreaker
+
+import (
+	"errors"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/sony/gobreaker/v2"
+)
+
+var ErrUnknownBreaker = errors.New("no circuit breaker registered with that name")
+
+// BreakerMetrics summarises a breaker's history since it was created, for
+// example for SLO dashboards. TimeInState includes the time spent so far in
+// the current state, and Transitions counts how many times the breaker has
+// entered each state.
+type BreakerMetrics struct {
+	State       gobreaker.State
+	TimeInState map[gobreaker.State]time.Duration
+	Transitions map[gobreaker.State]int
+}
+
+// stateMetrics accumulates BreakerMetrics from a breaker's state changes.
+type stateMetrics struct {
+	mu          sync.Mutex
+	state       gobreaker.State
+	since       time.Time
+	timeInState map[gobreaker.State]time.Duration
+	transitions map[gobreaker.State]int
+}
+
+func newStateMetrics() *stateMetrics {
+	return &stateMetrics{
+		state:       gobreaker.StateClosed,
+		since:       time.Now(),
+		timeInState: map[gobreaker.State]time.Duration{},
+		transitions: map[gobreaker.State]int{},
+	}
+}
+
+func (m *stateMetrics) onStateChange(to gobreaker.State) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.timeInState[m.state] += now.Sub(m.since)
+	m.transitions[to]++
+	m.state = to
+	m.since = now
+}
+
+// timeInOpenState returns how long the breaker has been open for, or false
+// if it is not open.
+func (m *stateMetrics) timeInOpenState() (time.Duration, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.state != gobreaker.StateOpen {
+		return 0, false
+	}
+
+	return time.Since(m.since), true
+}
+
+func (m *stateMetrics) snapshot() BreakerMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	timeInState := maps.Clone(m.timeInState)
+	timeInState[m.state] += time.Since(m.since)
+
+	return BreakerMetrics{
+		State:       m.state,
+		TimeInState: timeInState,
+		Transitions: maps.Clone(m.transitions),
+	}
+}
@@ -0,0 +1,55 @@
+package circuitbreaker
+
+import "sync"
+
+// Registry shares circuit breakers by name between transports, so clients
+// built separately, such as short-lived clients created per request, trip
+// and recover together.
+type Registry struct {
+	mu       sync.Mutex
+	breakers map[string]*breaker
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		breakers: map[string]*breaker{},
+	}
+}
+
+// get returns the breaker registered under the settings' name, creating it
+// from the settings if this is the first transport to ask for it.
+func (r *Registry) get(settings Settings) *breaker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, ok := r.breakers[settings.Name]
+	if !ok {
+		b = newBreaker(settings)
+		r.breakers[settings.Name] = b
+	}
+
+	return b
+}
+
+// Metrics returns the state history of the breaker registered under name.
+// Only breakers shared through a registry can be looked up, so give a
+// transport a Registry to expose the metrics of its breaker.
+func (r *Registry) Metrics(name string) (BreakerMetrics, error) {
+	r.mu.Lock()
+	b, ok := r.breakers[name]
+	r.mu.Unlock()
+
+	if !ok {
+		return BreakerMetrics{}, ErrUnknownBreaker
+	}
+
+	// an open breaker only moves to half-open when its state is next
+	// checked, so check it to bring the metrics up to date. A breaker with a
+	// backoff gate is held open by the gate instead, and checking would
+	// half-open it while the gate still rejects requests.
+	if b.gate == nil {
+		b.cb.State()
+	}
+
+	return b.metrics.snapshot(), nil
+}
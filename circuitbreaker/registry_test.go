@@ -0,0 +1,131 @@
+package circuitbreaker_test
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/JSainsburyPLC/danielchurm/go-http-client/circuitbreaker"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sony/gobreaker/v2"
+)
+
+var _ = Describe("Registry", func() {
+	It("shares trip state between transports with the same name", func() {
+		registry := circuitbreaker.NewRegistry()
+		settings := circuitbreaker.Settings{
+			Settings: gobreaker.Settings{Name: "upstream", ReadyToTrip: readyToTrip},
+			Registry: registry,
+		}
+
+		first := circuitbreaker.NewRoundTripper(&testRoundTripper{StatusCode: http.StatusInternalServerError}, settings)
+		second := circuitbreaker.NewRoundTripper(&testRoundTripper{StatusCode: http.StatusOK}, settings)
+
+		_, err := first.RoundTrip(nil)
+		Expect(err).ToNot(HaveOccurred(), "error returned on the first call")
+
+		_, err = second.RoundTrip(nil)
+		Expect(err).To(MatchError(gobreaker.ErrOpenState), "second transport did not share the open breaker")
+	})
+
+	It("keeps breakers with different names independent", func() {
+		registry := circuitbreaker.NewRegistry()
+
+		failing := circuitbreaker.NewRoundTripper(&testRoundTripper{StatusCode: http.StatusInternalServerError}, circuitbreaker.Settings{
+			Settings: gobreaker.Settings{Name: "failing", ReadyToTrip: readyToTrip},
+			Registry: registry,
+		})
+		healthy := circuitbreaker.NewRoundTripper(&testRoundTripper{StatusCode: http.StatusOK}, circuitbreaker.Settings{
+			Settings: gobreaker.Settings{Name: "healthy", ReadyToTrip: readyToTrip},
+			Registry: registry,
+		})
+
+		_, err := failing.RoundTrip(nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = healthy.RoundTrip(nil)
+		Expect(err).ToNot(HaveOccurred(), "breaker with a different name was tripped")
+	})
+
+	It("accumulates time in each state and transition counts", func() {
+		registry := circuitbreaker.NewRegistry()
+		statusCode := http.StatusInternalServerError
+		transport := circuitbreaker.NewRoundTripper(roundTripperFunc(func(*http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: statusCode}, nil
+		}), circuitbreaker.Settings{
+			Settings: gobreaker.Settings{Name: "upstream", ReadyToTrip: readyToTrip, Timeout: 50 * time.Millisecond},
+			Registry: registry,
+		})
+
+		time.Sleep(20 * time.Millisecond)
+		_, err := transport.RoundTrip(nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		// stays open until the timeout, then half-opens and closes on a
+		// successful probe
+		time.Sleep(60 * time.Millisecond)
+		statusCode = http.StatusOK
+		_, err = transport.RoundTrip(nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		metrics, err := registry.Metrics("upstream")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(metrics.State).To(Equal(gobreaker.StateClosed))
+		Expect(metrics.Transitions).To(Equal(map[gobreaker.State]int{
+			gobreaker.StateOpen:     1,
+			gobreaker.StateHalfOpen: 1,
+			gobreaker.StateClosed:   1,
+		}))
+		Expect(metrics.TimeInState[gobreaker.StateClosed]).To(BeNumerically(">=", 20*time.Millisecond), "closed time not accumulated")
+		Expect(metrics.TimeInState[gobreaker.StateOpen]).To(BeNumerically(">=", 50*time.Millisecond), "open time not accumulated")
+	})
+
+	It("reports the half-open transition of a breaker whose timeout has passed", func() {
+		registry := circuitbreaker.NewRegistry()
+		transport := circuitbreaker.NewRoundTripper(&testRoundTripper{StatusCode: http.StatusInternalServerError}, circuitbreaker.Settings{
+			Settings: gobreaker.Settings{Name: "upstream", ReadyToTrip: readyToTrip, Timeout: 10 * time.Millisecond},
+			Registry: registry,
+		})
+
+		_, err := transport.RoundTrip(nil)
+		Expect(err).ToNot(HaveOccurred())
+		time.Sleep(20 * time.Millisecond)
+
+		metrics, err := registry.Metrics("upstream")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(metrics.State).To(Equal(gobreaker.StateHalfOpen), "lazy half-open transition not reported")
+	})
+
+	It("reports a breaker held open by its backoff as open", func() {
+		var transitions []gobreaker.State
+		registry := circuitbreaker.NewRegistry()
+		transport := circuitbreaker.NewRoundTripper(&testRoundTripper{StatusCode: http.StatusInternalServerError}, circuitbreaker.Settings{
+			Settings: gobreaker.Settings{
+				Name:        "upstream",
+				ReadyToTrip: readyToTrip,
+				OnStateChange: func(name string, from gobreaker.State, to gobreaker.State) {
+					transitions = append(transitions, to)
+				},
+			},
+			Registry:        registry,
+			HalfOpenBackoff: circuitbreaker.ExponentialBackoff(time.Hour, time.Hour),
+		})
+
+		_, err := transport.RoundTrip(nil)
+		Expect(err).ToNot(HaveOccurred())
+		time.Sleep(time.Millisecond)
+
+		metrics, err := registry.Metrics("upstream")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(metrics.State).To(Equal(gobreaker.StateOpen), "breaker held open by the backoff reported as half-open")
+		Expect(transitions).To(Equal([]gobreaker.State{gobreaker.StateOpen}), "metrics lookup changed the breaker's state")
+
+		_, err = transport.RoundTrip(nil)
+		Expect(err).To(MatchError(gobreaker.ErrOpenState))
+	})
+
+	It("rejects names with no registered breaker", func() {
+		_, err := circuitbreaker.NewRegistry().Metrics("missing")
+		Expect(err).To(MatchError(circuitbreaker.ErrUnknownBreaker))
+	})
+})
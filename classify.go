@@ -0,0 +1,71 @@
+package go_http_client
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/sony/gobreaker/v2"
+)
+
+// FailureReason is the broad cause of a failed request, as reported by
+// ClassifyError.
+type FailureReason int
+
+const (
+	ReasonNone FailureReason = iota
+	ReasonUnknown
+	ReasonTimeout
+	ReasonConnection
+	ReasonBreakerOpen
+	ReasonStatus
+)
+
+func (r FailureReason) String() string {
+	switch r {
+	case ReasonNone:
+		return "none"
+	case ReasonTimeout:
+		return "timeout"
+	case ReasonConnection:
+		return "connection"
+	case ReasonBreakerOpen:
+		return "breaker_open"
+	case ReasonStatus:
+		return "status"
+	default:
+		return "unknown"
+	}
+}
+
+// ClassifyError inspects the chain of an error returned by the client or
+// its helpers to report why the request failed, so callers can switch on
+// the cause. A nil error is ReasonNone.
+func ClassifyError(err error) FailureReason {
+	if err == nil {
+		return ReasonNone
+	}
+
+	if errors.Is(err, gobreaker.ErrOpenState) || errors.Is(err, gobreaker.ErrTooManyRequests) {
+		return ReasonBreakerOpen
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return ReasonStatus
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrResponseBodyTimeout) ||
+		(errors.As(err, &netErr) && netErr.Timeout()) {
+		return ReasonTimeout
+	}
+
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	if errors.As(err, &opErr) || errors.As(err, &dnsErr) || errors.Is(err, ErrHostUnavailable) {
+		return ReasonConnection
+	}
+
+	return ReasonUnknown
+}
@@ -0,0 +1,70 @@
+package go_http_client_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	httpclient "github.com/JSainsburyPLC/danielchurm/go-http-client"
+	"github.com/JSainsburyPLC/danielchurm/go-http-client/circuitbreaker"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ClassifyError", func() {
+	It("classifies a client timeout", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(200 * time.Millisecond)
+		}))
+		defer server.Close()
+
+		_, err := baseBuilder().WithTimeout(20 * time.Millisecond).Build().Get(server.URL)
+		Expect(httpclient.ClassifyError(err)).To(Equal(httpclient.ReasonTimeout))
+	})
+
+	It("classifies a refused connection", func() {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+		addr := listener.Addr().String()
+		Expect(listener.Close()).To(Succeed())
+
+		_, err = baseBuilder().Build().Get("http://" + addr)
+		Expect(httpclient.ClassifyError(err)).To(Equal(httpclient.ReasonConnection))
+	})
+
+	It("classifies a host marked unavailable as a connection failure", func() {
+		availability := &httpclient.HostAvailability{}
+		availability.SetHostAvailability("down.example", false)
+
+		_, err := baseBuilder().WithHostAvailability(availability).Build().Get("http://down.example")
+		Expect(httpclient.ClassifyError(err)).To(Equal(httpclient.ReasonConnection))
+	})
+
+	It("classifies an open breaker", func() {
+		err := fmt.Errorf("request failed: %w", &circuitbreaker.BreakerOpenError{Key: "upstream"})
+		Expect(httpclient.ClassifyError(err)).To(Equal(httpclient.ReasonBreakerOpen))
+	})
+
+	It("classifies an unexpected status", func() {
+		err := httpclient.Ping(context.Background(), baseBuilder().Build(), statusServer(http.StatusServiceUnavailable))
+		Expect(httpclient.ClassifyError(err)).To(Equal(httpclient.ReasonStatus))
+	})
+
+	It("leaves other errors unknown and nil as none", func() {
+		Expect(httpclient.ClassifyError(errors.New("boom"))).To(Equal(httpclient.ReasonUnknown))
+		Expect(httpclient.ClassifyError(nil)).To(Equal(httpclient.ReasonNone))
+		Expect(httpclient.ReasonBreakerOpen.String()).To(Equal("breaker_open"))
+	})
+})
+
+func statusServer(statusCode int) string {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(statusCode)
+	}))
+	DeferCleanup(server.Close)
+	return server.URL
+}
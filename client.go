@@ -1,12 +1,18 @@
 package go_http_client
 
 import (
-	"httpclient/circuitbreaker"
+	"context"
+	"fmt"
+	"net"
 	"net/http"
+	"net/url"
+	"slices"
 	"time"
 
+	"github.com/JSainsburyPLC/danielchurm/go-http-client/circuitbreaker"
 	"github.com/JSainsburyPLC/go-logrus-wrapper/v2/roundtripper"
 	"github.com/newrelic/go-agent/v3/newrelic"
+	"golang.org/x/net/proxy"
 )
 
 var (
@@ -28,11 +34,50 @@ type CircuitBreakerSettings struct {
 	Settings circuitbreaker.Settings
 }
 
+type HeaderPassthroughSettings struct {
+	Names  []string
+	CtxKey any
+}
+
+type ExpectContinueSettings struct {
+	Enabled   bool
+	Threshold int64
+}
+
+type AuthRefreshSettings struct {
+	Token   func(ctx context.Context) (string, error)
+	Refresh func(ctx context.Context) error
+}
+
 type ClientBuilder struct {
 	Timeout              time.Duration
 	NewRelicEnabled      bool
 	SendSmartShopHeaders bool
 	CircuitBreaker       CircuitBreakerSettings
+	Pool                 PoolSettings
+	HostPools            map[string]PoolSettings
+	Headers              http.Header
+	HostOverride         string
+	HeaderPassthrough    HeaderPassthroughSettings
+	NewRelicAttributes   func(*http.Request) map[string]any
+	Drainer              *Drainer
+	HostAvailability     *HostAvailability
+	PreSendHook          func(*http.Request)
+	Recorder             *Recorder
+	Cassette             CassetteSettings
+	Stats                *Stats
+	SingleFlight         bool
+	TransportOrder       []string
+	BaseContext          context.Context
+	MaxIdleTime          time.Duration
+	SniffContentType     bool
+	ResumableDownloads   bool
+	NormalizeHeaders     bool
+	ExpectContinue       ExpectContinueSettings
+	AuthRefresh          AuthRefreshSettings
+	ResponseMiddleware   []ResponseMiddleware
+	FaultInjection       FaultSettings
+	SlowRequestThreshold time.Duration
 }
 
 func (cb ClientBuilder) WithTimeout(timeout time.Duration) ClientBuilder {
@@ -45,6 +90,15 @@ func (cb ClientBuilder) DisableNewRelic() ClientBuilder {
 	return cb
 }
 
+// WithNewRelicAttributes enables New Relic and adds the attributes fn returns
+// for each request to its external segment, for example a tenant ID or API
+// version. Values must be numbers, strings or booleans.
+func (cb ClientBuilder) WithNewRelicAttributes(fn func(*http.Request) map[string]any) ClientBuilder {
+	cb.NewRelicEnabled = true
+	cb.NewRelicAttributes = fn
+	return cb
+}
+
 func (cb ClientBuilder) DisableSmartShopHeaders() ClientBuilder {
 	cb.SendSmartShopHeaders = false
 	return cb
@@ -55,28 +109,658 @@ func (cb ClientBuilder) DisableCircuitBreaker() ClientBuilder {
 	return cb
 }
 
+// WithCircuitBreakerSettings replaces the circuit breaker settings, keeping
+// any registry set by WithBreakerRegistry unless settings has its own.
 func (cb ClientBuilder) WithCircuitBreakerSettings(settings circuitbreaker.Settings) ClientBuilder {
+	if settings.Registry == nil {
+		settings.Registry = cb.CircuitBreaker.Settings.Registry
+	}
+
 	cb.CircuitBreaker.Enabled = true
 	cb.CircuitBreaker.Settings = settings
 	return cb
 }
 
-func (cb ClientBuilder) Build() *http.Client {
-	client := &http.Client{
-		Timeout: cb.Timeout,
+// WithBreakerRegistry shares the client's circuit breaker with every other
+// client built with the same registry and breaker name.
+func (cb ClientBuilder) WithBreakerRegistry(registry *circuitbreaker.Registry) ClientBuilder {
+	cb.CircuitBreaker.Enabled = true
+	cb.CircuitBreaker.Settings.Registry = registry
+	return cb
+}
+
+// WithDrainer lets drainer reject the client's new requests before they
+// reach any other layer of the transport chain.
+func (cb ClientBuilder) WithDrainer(drainer *Drainer) ClientBuilder {
+	cb.Drainer = drainer
+	return cb
+}
+
+// WithHostAvailability fails the client's requests to hosts marked down in
+// availability before they reach the circuit breaker or the network.
+func (cb ClientBuilder) WithHostAvailability(availability *HostAvailability) ClientBuilder {
+	cb.HostAvailability = availability
+	return cb
+}
+
+// WithFaultInjection delays, fails or answers requests at random with the
+// rates in settings, which only take effect with settings.Enabled set. Faults
+// are injected below the circuit breaker so they exercise it too.
+func (cb ClientBuilder) WithFaultInjection(settings FaultSettings) ClientBuilder {
+	cb.FaultInjection = settings
+	return cb
+}
+
+// WithSlowRequestLog logs the method, URL, status and duration of every
+// request that takes longer than threshold to get its response headers, as
+// measured around every other layer of the transport chain.
+func (cb ClientBuilder) WithSlowRequestLog(threshold time.Duration) ClientBuilder {
+	cb.SlowRequestThreshold = threshold
+	return cb
+}
+
+// WithStats counts the client's requests, errors and circuit breaker
+// activity in stats. A breaker shared through a registry reports its state
+// changes to the stats of the first client built with it.
+func (cb ClientBuilder) WithStats(stats *Stats) ClientBuilder {
+	cb.Stats = stats
+	return cb
+}
+
+// WithSingleFlight coalesces concurrent GET and HEAD requests for the same
+// URL so that only one reaches the upstream and every caller shares its
+// response, for example during a cache stampede. Requests are matched on
+// method and URL alone, so it must not be used when headers such as
+// Authorization change the response.
+func (cb ClientBuilder) WithSingleFlight() ClientBuilder {
+	cb.SingleFlight = true
+	return cb
+}
+
+// WithBaseContext binds ctx to every request, for example one carrying a
+// tenant or cancelled on shutdown. Its values are visible to every layer
+// unless the request's context has a value for the same key, and its
+// cancellation aborts requests in flight.
+func (cb ClientBuilder) WithBaseContext(ctx context.Context) ClientBuilder {
+	cb.BaseContext = ctx
+	return cb
+}
+
+// WithMaxIdleTime closes all of the client's idle connections once it has
+// made no requests for d, freeing them in rarely used clients. Unlike
+// IdleConnTimeout it measures inactivity of the whole client rather than of
+// each connection. The layer must stay directly above the base transport.
+func (cb ClientBuilder) WithMaxIdleTime(d time.Duration) ClientBuilder {
+	cb.MaxIdleTime = d
+	return cb
+}
+
+// WithPreSendHook calls hook with every request just before it reaches the
+// base transport, after all other layers have decorated it, for example for
+// audit logging.
+func (cb ClientBuilder) WithPreSendHook(hook func(*http.Request)) ClientBuilder {
+	cb.PreSendHook = hook
+	return cb
+}
+
+// WithRecorder records every request the client sends with recorder, just
+// above the base transport so that the recording holds the fully decorated
+// request.
+func (cb ClientBuilder) WithRecorder(recorder *Recorder) ClientBuilder {
+	cb.Recorder = recorder
+	return cb
+}
+
+// WithRecordCassette sends requests to the real upstreams as usual and writes
+// every interaction to the cassette file at path, replacing its contents.
+func (cb ClientBuilder) WithRecordCassette(path string) ClientBuilder {
+	cb.Cassette.Mode = CassetteRecord
+	cb.Cassette.Path = path
+	return cb
+}
+
+// WithReplayCassette replaces the base transport with one answering requests
+// from the cassette file at path, failing with ErrNoCassetteMatch for any
+// request that was not recorded.
+func (cb ClientBuilder) WithReplayCassette(path string) ClientBuilder {
+	cb.Cassette.Mode = CassetteReplay
+	cb.Cassette.Path = path
+	return cb
+}
+
+// WithCassetteMatcher replaces how replayed requests are matched to the
+// recorded ones.
+func (cb ClientBuilder) WithCassetteMatcher(matcher CassetteMatcher) ClientBuilder {
+	cb.Cassette.Matcher = matcher
+	return cb
+}
+
+// WithCassetteRedactedHeaders replaces which headers have their values
+// redacted from recorded cassettes. Calling it with no names records every
+// header as sent.
+func (cb ClientBuilder) WithCassetteRedactedHeaders(names ...string) ClientBuilder {
+	cb.Cassette.RedactHeaders = append([]string{}, names...)
+	return cb
+}
+
+// WithPoolSettings applies the non-zero fields of settings on top of any
+// pool settings already configured, so a shared base configuration can be
+// refined by later calls. A false flag in settings is treated as unset, so
+// use WithKeepAlives, WithCompression or WithDualStack to switch a flag set
+// by an earlier call back off.
+func (cb ClientBuilder) WithPoolSettings(settings PoolSettings) ClientBuilder {
+	cb.Pool = cb.Pool.merge(settings)
+	return cb
+}
+
+// WithKeepAlives sets whether the pool keeps connections alive between
+// requests, overriding DisableKeepAlives from earlier pool settings.
+func (cb ClientBuilder) WithKeepAlives(enabled bool) ClientBuilder {
+	cb.Pool.DisableKeepAlives = !enabled
+	return cb
+}
+
+// WithCompression sets whether the transport requests and transparently
+// decompresses gzip responses, overriding DisableCompression from earlier
+// pool settings.
+func (cb ClientBuilder) WithCompression(enabled bool) ClientBuilder {
+	cb.Pool.DisableCompression = !enabled
+	return cb
+}
+
+// WithDualStack sets whether the dialer's IPv6 and IPv4 fallback is tuned
+// with FallbackDelay, overriding DualStack from earlier pool settings.
+func (cb ClientBuilder) WithDualStack(enabled bool) ClientBuilder {
+	cb.Pool.DualStack = enabled
+	return cb
+}
+
+// WithPerHostPool gives each host in hostPools, keyed by host or host:port,
+// a connection pool of its own configured by its settings applied on top of
+// defaultPool. Every other host shares a pool configured by defaultPool, which
+// is merged like WithPoolSettings. Settings that apply to the whole client
+// rather than a pool, such as CompressionEncodings and ResponseBodyTimeout,
+// are only read from defaultPool.
+func (cb ClientBuilder) WithPerHostPool(hostPools map[string]PoolSettings, defaultPool PoolSettings) ClientBuilder {
+	cb.HostPools = make(map[string]PoolSettings, len(hostPools))
+	for host, settings := range hostPools {
+		cb.HostPools[host] = settings
+	}
+
+	cb.Pool = cb.Pool.merge(defaultPool)
+	return cb
+}
+
+// WithUnixSocket sends every request over the unix domain socket at path,
+// so callers can use URLs such as http://unix/path.
+func (cb ClientBuilder) WithUnixSocket(path string) ClientBuilder {
+	cb.Pool.UnixSocket = path
+	return cb
+}
+
+// WithSOCKS5Proxy routes every connection through the SOCKS5 proxy at addr.
+// auth may be nil when the proxy does not require authentication.
+func (cb ClientBuilder) WithSOCKS5Proxy(addr string, auth *proxy.Auth) ClientBuilder {
+	cb.Pool.SOCKS5ProxyAddr = addr
+	cb.Pool.SOCKS5ProxyAuth = auth
+	return cb
+}
+
+// WithHeaderPassthrough forwards the named headers of an incoming request,
+// such as traceparent and baggage, onto every outgoing request made with its
+// context. The incoming headers are read as an http.Header stored in the
+// context under ctxKey, typically by server middleware. A name ending in *
+// matches every header with that prefix, such as x-b3-*. Passed through
+// headers take precedence over context and static headers, but never over
+// headers set on the request itself.
+func (cb ClientBuilder) WithHeaderPassthrough(names []string, ctxKey any) ClientBuilder {
+	cb.HeaderPassthrough = HeaderPassthroughSettings{
+		Names:  append([]string(nil), names...),
+		CtxKey: ctxKey,
+	}
+	return cb
+}
+
+// WithContentTypeSniffing sets the Content-Type of requests sent with a
+// body but without one: application/json for bodies starting with { or [,
+// otherwise the type http.DetectContentType reports for their first bytes.
+func (cb ClientBuilder) WithContentTypeSniffing() ClientBuilder {
+	cb.SniffContentType = true
+	return cb
+}
+
+// WithResumableDownloads continues a GET response body that fails part way
+// through with a Range request for the remaining bytes, up to
+// MaxDownloadResumes times, so large downloads are not restarted from
+// scratch. It only applies to servers advertising Accept-Ranges: bytes, and
+// not to bodies the transport decompressed.
+func (cb ClientBuilder) WithResumableDownloads() ClientBuilder {
+	cb.ResumableDownloads = true
+	return cb
+}
+
+// WithHeaderNormalization canonicalizes the header keys of every request and
+// removes values repeated for the same key, after every other layer but the
+// request recorders and pre-send hook has added its headers.
+func (cb ClientBuilder) WithHeaderNormalization() ClientBuilder {
+	cb.NormalizeHeaders = true
+	return cb
+}
+
+// WithExpectContinue sends Expect: 100-continue with request bodies larger
+// than threshold bytes, or of unknown length, so that the server can reject
+// an upload before it is sent. The transport waits for the server's go-ahead
+// for up to ExpectContinueTimeout (1 second by default) and then sends the
+// body anyway; a zero timeout sends it straight away. Servers that reject the
+// expectation itself with a 417 are sent the body again without it, when the
+// request has a GetBody to replay it.
+func (cb ClientBuilder) WithExpectContinue(threshold int64) ClientBuilder {
+	cb.ExpectContinue = ExpectContinueSettings{Enabled: true, Threshold: threshold}
+	return cb
+}
+
+// WithAuthRefresh sends the bearer token returned by token with every
+// request that has no Authorization header of its own. When an idempotent
+// request is rejected with a 401, refresh is called unless the token has
+// changed since the request was sent, and the request is sent one more time
+// with the token then current. Requests rejected while a refresh is running
+// wait for it rather than starting another. A request still rejected after
+// that returns its 401, as does every rejected request when refresh is nil.
+func (cb ClientBuilder) WithAuthRefresh(token func(ctx context.Context) (string, error), refresh func(ctx context.Context) error) ClientBuilder {
+	cb.AuthRefresh = AuthRefreshSettings{Token: token, Refresh: refresh}
+	return cb
+}
+
+// WithResponseMiddleware adds middleware that process every response in the
+// order given, after any added by earlier calls, for example to record
+// metrics, unwrap an envelope or map error codes. Middleware sees responses
+// of every status, but not requests that failed without one.
+func (cb ClientBuilder) WithResponseMiddleware(middleware ...ResponseMiddleware) ClientBuilder {
+	cb.ResponseMiddleware = append(append([]ResponseMiddleware(nil), cb.ResponseMiddleware...), middleware...)
+	return cb
+}
+
+// WithHostOverride sends host as the Host header of every request and
+// presents its hostname for TLS, regardless of the URL, for example to reach
+// a virtual host through an IP address.
+func (cb ClientBuilder) WithHostOverride(host string) ClientBuilder {
+	cb.HostOverride = host
+
+	serverName := host
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		serverName = hostname
+	}
+	cb.Pool.TLSServerName = serverName
+
+	return cb
+}
+
+// WithMinTLSVersion refuses to connect over TLS versions older than
+// version, such as tls.VersionTLS13, failing handshakes with servers that
+// cannot meet it with a *TLSVersionError.
+func (cb ClientBuilder) WithMinTLSVersion(version uint16) ClientBuilder {
+	cb.Pool.MinTLSVersion = version
+	return cb
+}
+
+// WithHeaders adds headers sent on every request. Repeated calls merge into
+// the headers already configured, with the last call winning for a key.
+func (cb ClientBuilder) WithHeaders(headers http.Header) ClientBuilder {
+	merged := cb.Headers.Clone()
+	if merged == nil {
+		merged = http.Header{}
+	}
+
+	for key, values := range headers {
+		merged[http.CanonicalHeaderKey(key)] = append([]string(nil), values...)
+	}
+
+	cb.Headers = merged
+	return cb
+}
+
+// WithStaticHeader adds a single header sent on every request, merging like
+// WithHeaders.
+func (cb ClientBuilder) WithStaticHeader(key, value string) ClientBuilder {
+	return cb.WithHeaders(http.Header{key: {value}})
+}
+
+// WithDefaultAccept sends value as the Accept header on every request that
+// does not set its own, for upstreams that content-negotiate.
+func (cb ClientBuilder) WithDefaultAccept(value string) ClientBuilder {
+	return cb.WithStaticHeader("Accept", value)
+}
+
+// The names of the transport layers, as reported by TransportChain and
+// accepted by WithTransportOrder, innermost first.
+const (
+	LayerFaultInjection      = "fault-injection"
+	LayerMinTLSVersion       = "min-tls-version"
+	LayerIdleRelease         = "idle-release"
+	LayerCassetteRecord      = "cassette-record"
+	LayerRecorder            = "recorder"
+	LayerPreSend             = "pre-send"
+	LayerHeaderNormalization = "header-normalization"
+	LayerDecompression       = "decompression"
+	LayerBodyTimeout         = "body-timeout"
+	LayerContentTypeSniffing = "content-type-sniffing"
+	LayerExpectContinue      = "expect-continue"
+	LayerHostOverride        = "host-override"
+	LayerHeaders             = "headers"
+	LayerHeaderPassthrough   = "header-passthrough"
+	LayerNewRelic            = "newrelic"
+	LayerSmartShopHeaders    = "smartshop-headers"
+	LayerCircuitBreaker      = "circuitbreaker"
+	LayerResumableDownloads  = "resumable-downloads"
+	LayerAuthRefresh         = "auth-refresh"
+	LayerHostAvailability    = "host-availability"
+	LayerDrain               = "drain"
+	LayerSingleFlight        = "singleflight"
+	LayerResponseMiddleware  = "response-middleware"
+	LayerStats               = "stats"
+	LayerSlowRequestLog      = "slow-request-log"
+	LayerBaseContext         = "base-context"
+)
+
+// LayerBase and LayerCassetteReplay end the chain TransportChain reports.
+// They are always innermost, so they cannot be reordered.
+const (
+	LayerBase           = "base"
+	LayerCassetteReplay = "cassette-replay"
+)
+
+var layerNames = []string{
+	LayerFaultInjection,
+	LayerMinTLSVersion,
+	LayerIdleRelease,
+	LayerCassetteRecord,
+	LayerRecorder,
+	LayerPreSend,
+	LayerHeaderNormalization,
+	LayerDecompression,
+	LayerBodyTimeout,
+	LayerContentTypeSniffing,
+	LayerExpectContinue,
+	LayerHostOverride,
+	LayerHeaders,
+	LayerHeaderPassthrough,
+	LayerNewRelic,
+	LayerSmartShopHeaders,
+	LayerCircuitBreaker,
+	LayerResumableDownloads,
+	LayerAuthRefresh,
+	LayerHostAvailability,
+	LayerDrain,
+	LayerSingleFlight,
+	LayerResponseMiddleware,
+	LayerStats,
+	LayerSlowRequestLog,
+	LayerBaseContext,
+}
+
+// WithTransportOrder overrides the order of the transport layers, for
+// advanced uses such as recording requests before the static headers are
+// added. names lists layers as TransportChain does, outermost first. The
+// named layers are wrapped outermost in the order given, and the enabled
+// layers not named keep their default order inside them. Names of layers
+// that are not enabled are ignored, and it panics on a name that is not one
+// of the Layer constants, so a typo does not silently keep the default
+// order.
+func (cb ClientBuilder) WithTransportOrder(names ...string) ClientBuilder {
+	for _, name := range names {
+		if !slices.Contains(layerNames, name) {
+			panic(fmt.Sprintf("go_http_client: unknown transport layer %q", name))
+		}
+	}
+
+	cb.TransportOrder = append([]string(nil), names...)
+	return cb
+}
+
+type transportLayer struct {
+	name string
+	wrap func(http.RoundTripper) http.RoundTripper
+}
+
+// layers returns the transport layers enabled on the builder, innermost
+// first.
+func (cb ClientBuilder) layers() []transportLayer {
+	var layers []transportLayer
+
+	if cb.FaultInjection.Enabled {
+		layers = append(layers, transportLayer{LayerFaultInjection, func(rt http.RoundTripper) http.RoundTripper {
+			return newFaultInjectionTransport(rt, cb.FaultInjection)
+		}})
+	}
+
+	if cb.Pool.MinTLSVersion != 0 {
+		layers = append(layers, transportLayer{LayerMinTLSVersion, func(rt http.RoundTripper) http.RoundTripper {
+			return newMinTLSVersionTransport(rt, cb.Pool.MinTLSVersion)
+		}})
+	}
+
+	if cb.MaxIdleTime > 0 {
+		layers = append(layers, transportLayer{LayerIdleRelease, func(rt http.RoundTripper) http.RoundTripper {
+			return newIdleReleaseTransport(rt, cb.MaxIdleTime)
+		}})
+	}
+
+	if cb.Cassette.Mode == CassetteRecord {
+		layers = append(layers, transportLayer{LayerCassetteRecord, func(rt http.RoundTripper) http.RoundTripper {
+			return newCassetteRecordTransport(rt, cb.Cassette.Path, cb.Cassette.RedactHeaders)
+		}})
+	}
+
+	if cb.Recorder != nil {
+		layers = append(layers, transportLayer{LayerRecorder, func(rt http.RoundTripper) http.RoundTripper {
+			return newRecorderTransport(rt, cb.Recorder)
+		}})
+	}
+
+	if cb.PreSendHook != nil {
+		layers = append(layers, transportLayer{LayerPreSend, func(rt http.RoundTripper) http.RoundTripper {
+			return newPreSendTransport(rt, cb.PreSendHook)
+		}})
+	}
+
+	if cb.NormalizeHeaders {
+		layers = append(layers, transportLayer{LayerHeaderNormalization, newHeaderNormalizationTransport})
+	}
+
+	if cb.Pool.DisableCompression && len(cb.Pool.CompressionEncodings) > 0 {
+		layers = append(layers, transportLayer{LayerDecompression, func(rt http.RoundTripper) http.RoundTripper {
+			return newDecompressionTransport(rt, cb.Pool.CompressionEncodings)
+		}})
+	}
+
+	if cb.Pool.ResponseBodyTimeout > 0 {
+		layers = append(layers, transportLayer{LayerBodyTimeout, func(rt http.RoundTripper) http.RoundTripper {
+			return newBodyTimeoutTransport(rt, cb.Pool.ResponseBodyTimeout)
+		}})
+	}
+
+	if cb.SniffContentType {
+		layers = append(layers, transportLayer{LayerContentTypeSniffing, newContentTypeSniffingTransport})
+	}
+
+	if cb.ExpectContinue.Enabled {
+		layers = append(layers, transportLayer{LayerExpectContinue, func(rt http.RoundTripper) http.RoundTripper {
+			return newExpectContinueTransport(rt, cb.ExpectContinue.Threshold)
+		}})
+	}
+
+	if cb.HostOverride != "" {
+		layers = append(layers, transportLayer{LayerHostOverride, func(rt http.RoundTripper) http.RoundTripper {
+			return newHostOverrideTransport(rt, cb.HostOverride)
+		}})
 	}
 
-	if cb.NewRelicEnabled {
-		client.Transport = newrelic.NewRoundTripper(client.Transport)
+	layers = append(layers, transportLayer{LayerHeaders, func(rt http.RoundTripper) http.RoundTripper {
+		return newHeaderTransport(rt, cb.Headers)
+	}})
+
+	if len(cb.HeaderPassthrough.Names) > 0 {
+		layers = append(layers, transportLayer{LayerHeaderPassthrough, func(rt http.RoundTripper) http.RoundTripper {
+			return newHeaderPassthroughTransport(rt, cb.HeaderPassthrough.Names, cb.HeaderPassthrough.CtxKey)
+		}})
+	}
+
+	if cb.NewRelicEnabled && cb.NewRelicAttributes != nil {
+		layers = append(layers, transportLayer{LayerNewRelic, func(rt http.RoundTripper) http.RoundTripper {
+			return newNewRelicTransport(rt, cb.NewRelicAttributes)
+		}})
+	} else if cb.NewRelicEnabled {
+		layers = append(layers, transportLayer{LayerNewRelic, newrelic.NewRoundTripper})
 	}
 
 	if cb.SendSmartShopHeaders {
-		client.Transport = roundtripper.Wrap(client.Transport)
+		layers = append(layers, transportLayer{LayerSmartShopHeaders, roundtripper.Wrap})
 	}
 
 	if cb.CircuitBreaker.Enabled {
-		client.Transport = circuitbreaker.NewRoundTripper(client.Transport, cb.CircuitBreaker.Settings)
+		layers = append(layers, transportLayer{LayerCircuitBreaker, func(rt http.RoundTripper) http.RoundTripper {
+			settings := cb.CircuitBreaker.Settings
+			if cb.Stats != nil {
+				settings.OnStateChange = cb.Stats.observeStateChange(settings.OnStateChange)
+			}
+
+			return circuitbreaker.NewRoundTripper(rt, settings)
+		}})
+	}
+
+	if cb.ResumableDownloads {
+		layers = append(layers, transportLayer{LayerResumableDownloads, newResumableDownloadTransport})
+	}
+
+	if cb.AuthRefresh.Token != nil {
+		layers = append(layers, transportLayer{LayerAuthRefresh, func(rt http.RoundTripper) http.RoundTripper {
+			return newAuthRefreshTransport(rt, cb.AuthRefresh.Token, cb.AuthRefresh.Refresh)
+		}})
+	}
+
+	if cb.HostAvailability != nil {
+		layers = append(layers, transportLayer{LayerHostAvailability, func(rt http.RoundTripper) http.RoundTripper {
+			return newHostAvailabilityTransport(rt, cb.HostAvailability)
+		}})
+	}
+
+	if cb.Drainer != nil {
+		layers = append(layers, transportLayer{LayerDrain, func(rt http.RoundTripper) http.RoundTripper {
+			return newDrainTransport(rt, cb.Drainer)
+		}})
+	}
+
+	if cb.SingleFlight {
+		layers = append(layers, transportLayer{LayerSingleFlight, newSingleFlightTransport})
 	}
 
-	return client
+	if len(cb.ResponseMiddleware) > 0 {
+		layers = append(layers, transportLayer{LayerResponseMiddleware, func(rt http.RoundTripper) http.RoundTripper {
+			return newResponseMiddlewareTransport(rt, cb.ResponseMiddleware)
+		}})
+	}
+
+	if cb.Stats != nil {
+		layers = append(layers, transportLayer{LayerStats, func(rt http.RoundTripper) http.RoundTripper {
+			return newStatsTransport(rt, cb.Stats)
+		}})
+	}
+
+	if cb.SlowRequestThreshold > 0 {
+		layers = append(layers, transportLayer{LayerSlowRequestLog, func(rt http.RoundTripper) http.RoundTripper {
+			return newSlowRequestLogTransport(rt, cb.SlowRequestThreshold)
+		}})
+	}
+
+	if cb.BaseContext != nil {
+		layers = append(layers, transportLayer{LayerBaseContext, func(rt http.RoundTripper) http.RoundTripper {
+			return newBaseContextTransport(rt, cb.BaseContext)
+		}})
+	}
+
+	if len(cb.TransportOrder) > 0 {
+		layers = reorderLayers(layers, cb.TransportOrder)
+	}
+
+	return layers
+}
+
+// reorderLayers moves the layers named in order, outermost first, to the
+// outside of layers, which is innermost first.
+func reorderLayers(layers []transportLayer, order []string) []transportLayer {
+	var named []transportLayer
+	for _, name := range order {
+		for i, layer := range layers {
+			if layer.name == name {
+				named = append(named, layer)
+				layers = append(layers[:i:i], layers[i+1:]...)
+				break
+			}
+		}
+	}
+
+	for i := len(named) - 1; i >= 0; i-- {
+		layers = append(layers, named[i])
+	}
+
+	return layers
+}
+
+// TransportChain returns the names of the transport layers Build wires up,
+// outermost first and ending with the base transport, or the cassette that
+// replaces it.
+func (cb ClientBuilder) TransportChain() []string {
+	layers := cb.layers()
+
+	chain := make([]string, 0, len(layers)+1)
+	for i := len(layers) - 1; i >= 0; i-- {
+		chain = append(chain, layers[i].name)
+	}
+
+	if cb.Cassette.Mode == CassetteReplay {
+		return append(chain, LayerCassetteReplay)
+	}
+
+	return append(chain, LayerBase)
+}
+
+func (cb ClientBuilder) baseTransport() http.RoundTripper {
+	if cb.Cassette.Mode == CassetteReplay {
+		return newCassetteReplayTransport(cb.Cassette.Path, cb.Cassette.Matcher)
+	}
+
+	if len(cb.HostPools) > 0 {
+		hostPools := newHostPoolTransport(cb.Pool, cb.HostPools)
+		return newFreshConnectionTransport(hostPools, hostPools.transportFor)
+	}
+
+	base := cb.pooledTransport()
+	return newFreshConnectionTransport(base, func(*url.URL) *http.Transport { return base })
+}
+
+// pooledTransport shares http.DefaultTransport, and its idle pool, between
+// every client that leaves the pool settings alone, so short-lived clients
+// do not each hold connections of their own. A client releasing its idle
+// connections with WithMaxIdleTime gets a pool of its own, so it never
+// closes connections other clients are keeping alive.
+func (cb ClientBuilder) pooledTransport() *http.Transport {
+	if cb.Pool.isZero() && cb.MaxIdleTime <= 0 {
+		if shared, ok := http.DefaultTransport.(*http.Transport); ok {
+			return shared
+		}
+	}
+
+	return newBaseTransport(cb.Pool)
+}
+
+func (cb ClientBuilder) Build() *http.Client {
+	transport := cb.baseTransport()
+	for _, layer := range cb.layers() {
+		transport = layer.wrap(transport)
+	}
+
+	return &http.Client{
+		Timeout:   cb.Timeout,
+		Transport: transport,
+	}
 }
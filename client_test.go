@@ -0,0 +1,119 @@
+package go_http_client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	httpclient "github.com/JSainsburyPLC/danielchurm/go-http-client"
+	"github.com/JSainsburyPLC/danielchurm/go-http-client/circuitbreaker"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sony/gobreaker/v2"
+
+	"testing"
+)
+
+func TestClient(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Client")
+}
+
+var _ = Describe("Client Builder", func() {
+	It("reports the default transport chain", func() {
+		Expect(httpclient.Default.TransportChain()).To(Equal([]string{
+			"circuitbreaker", "smartshop-headers", "newrelic", "headers", "base",
+		}))
+	})
+
+	It("reports only the enabled transport layers", func() {
+		chain := baseBuilder().TransportChain()
+		Expect(chain).To(Equal([]string{"headers", "base"}))
+
+		chain = httpclient.Default.DisableSmartShopHeaders().DisableCircuitBreaker().TransportChain()
+		Expect(chain).To(Equal([]string{"newrelic", "headers", "base"}))
+	})
+
+	It("reorders the transport layers", func() {
+		chain := httpclient.Default.
+			WithTransportOrder(httpclient.LayerNewRelic, httpclient.LayerStats, httpclient.LayerHeaders).
+			TransportChain()
+		Expect(chain).To(Equal([]string{"newrelic", "headers", "circuitbreaker", "smartshop-headers", "base"}))
+	})
+
+	It("rejects unknown transport layer names", func() {
+		Expect(func() { baseBuilder().WithTransportOrder("header-passthru") }).To(PanicWith(ContainSubstring(`"header-passthru"`)))
+		Expect(func() { baseBuilder().WithTransportOrder(httpclient.LayerBase) }).To(Panic(), "the base transport cannot be reordered")
+	})
+
+	It("changes what the layers observe when reordered", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+		defer server.Close()
+
+		get := func(builder httpclient.ClientBuilder) {
+			resp, err := builder.Build().Get(server.URL)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.Body.Close()).To(Succeed())
+		}
+
+		inner := &httpclient.Recorder{}
+		get(baseBuilder().WithStaticHeader("X-Static", "1").WithRecorder(inner))
+		Expect(inner.Requests()[0].Header.Get("X-Static")).To(Equal("1"), "recorder at the bottom missed the static header")
+
+		outer := &httpclient.Recorder{}
+		get(baseBuilder().WithStaticHeader("X-Static", "1").WithRecorder(outer).WithTransportOrder(httpclient.LayerRecorder))
+		Expect(outer.Requests()[0].Header.Get("X-Static")).To(BeEmpty(), "recorder moved outermost saw the static header")
+	})
+
+	It("reports the method and URL of failed requests", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+		defer server.Close()
+
+		client := httpclient.Default.DisableNewRelic().DisableSmartShopHeaders().Build()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/resource", nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = client.Do(req)
+		Expect(err).To(MatchError(ContainSubstring(`Get "`+server.URL+`/resource"`)), "method and URL missing from error")
+		Expect(errors.Is(err, context.Canceled)).To(BeTrue(), "underlying error not unwrappable")
+
+		var urlErr *url.Error
+		Expect(errors.As(err, &urlErr)).To(BeTrue(), "error is not a *url.Error")
+		Expect(urlErr.Op).To(Equal("Get"))
+	})
+
+	It("shares circuit breaker state between clients using the same registry", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		registry := circuitbreaker.NewRegistry()
+		builder := baseBuilder().
+			WithCircuitBreakerSettings(circuitbreaker.Settings{
+				Settings: gobreaker.Settings{Name: "upstream", ReadyToTrip: func(gobreaker.Counts) bool { return true }},
+			}).
+			WithBreakerRegistry(registry)
+
+		resp, err := builder.Build().Get(server.URL)
+		Expect(err).ToNot(HaveOccurred(), "error returned on the first call")
+		Expect(resp.Body.Close()).To(Succeed())
+
+		_, err = builder.Build().Get(server.URL)
+		Expect(err).To(MatchError(gobreaker.ErrOpenState), "second client did not share the open breaker")
+	})
+})
+
+// builder with every middleware disabled, leaving only the base transport
+func baseBuilder() httpclient.ClientBuilder {
+	return httpclient.Default.
+		DisableNewRelic().
+		DisableSmartShopHeaders().
+		DisableCircuitBreaker()
+}
@@ -0,0 +1,221 @@
+package go_http_client
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/JSainsburyPLC/danielchurm/go-http-client/circuitbreaker"
+	"github.com/sony/gobreaker/v2"
+)
+
+// Duration is a time.Duration read from and written as a string such as
+// "30s", so that it can be set in JSON and YAML configuration.
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(parsed)
+	return nil
+}
+
+// Config holds the serialisable client settings, for example loaded from
+// YAML or JSON. Zero values keep the settings of Default.
+type Config struct {
+	Timeout                 Duration             `json:"timeout" yaml:"timeout"`
+	DisableNewRelic         bool                 `json:"disable_new_relic" yaml:"disable_new_relic"`
+	DisableSmartShopHeaders bool                 `json:"disable_smartshop_headers" yaml:"disable_smartshop_headers"`
+	Headers                 map[string]string    `json:"headers" yaml:"headers"`
+	CircuitBreaker          CircuitBreakerConfig `json:"circuit_breaker" yaml:"circuit_breaker"`
+	Pool                    PoolConfig           `json:"pool" yaml:"pool"`
+}
+
+type CircuitBreakerConfig struct {
+	Disabled                bool     `json:"disabled" yaml:"disabled"`
+	Name                    string   `json:"name" yaml:"name"`
+	MaxRequests             uint32   `json:"max_requests" yaml:"max_requests"`
+	Interval                Duration `json:"interval" yaml:"interval"`
+	Timeout                 Duration `json:"timeout" yaml:"timeout"`
+	SlowCallThreshold       Duration `json:"slow_call_threshold" yaml:"slow_call_threshold"`
+	SlowCallRateToTrip      float64  `json:"slow_call_rate_to_trip" yaml:"slow_call_rate_to_trip"`
+	SlowCallMinimumRequests uint32   `json:"slow_call_minimum_requests" yaml:"slow_call_minimum_requests"`
+}
+
+// PoolConfig mirrors the PoolSettings that can be written down in
+// configuration. MinTLSVersion is a version such as "1.2" or "1.3", and a
+// negative FallbackDelay disables the dual stack fallback, as in
+// PoolSettings.
+type PoolConfig struct {
+	MaxIdleConns          int      `json:"max_idle_conns" yaml:"max_idle_conns"`
+	MaxIdleConnsPerHost   int      `json:"max_idle_conns_per_host" yaml:"max_idle_conns_per_host"`
+	MaxConnsPerHost       int      `json:"max_conns_per_host" yaml:"max_conns_per_host"`
+	IdleConnTimeout       Duration `json:"idle_conn_timeout" yaml:"idle_conn_timeout"`
+	TLSHandshakeTimeout   Duration `json:"tls_handshake_timeout" yaml:"tls_handshake_timeout"`
+	ResponseHeaderTimeout Duration `json:"response_header_timeout" yaml:"response_header_timeout"`
+	ConnectTimeout        Duration `json:"connect_timeout" yaml:"connect_timeout"`
+	ExpectContinueTimeout Duration `json:"expect_continue_timeout" yaml:"expect_continue_timeout"`
+	ResponseBodyTimeout   Duration `json:"response_body_timeout" yaml:"response_body_timeout"`
+	ReadIdleTimeout       Duration `json:"read_idle_timeout" yaml:"read_idle_timeout"`
+	PingTimeout           Duration `json:"ping_timeout" yaml:"ping_timeout"`
+	ReadBufferSize        int      `json:"read_buffer_size" yaml:"read_buffer_size"`
+	WriteBufferSize       int      `json:"write_buffer_size" yaml:"write_buffer_size"`
+	DisableKeepAlives     bool     `json:"disable_keep_alives" yaml:"disable_keep_alives"`
+	DisableCompression    bool     `json:"disable_compression" yaml:"disable_compression"`
+	CompressionEncodings  []string `json:"compression_encodings,omitempty" yaml:"compression_encodings,omitempty"`
+	DualStack             bool     `json:"dual_stack" yaml:"dual_stack"`
+	FallbackDelay         Duration `json:"fallback_delay" yaml:"fallback_delay"`
+	UnixSocket            string   `json:"unix_socket" yaml:"unix_socket"`
+	SOCKS5ProxyAddr       string   `json:"socks5_proxy_addr" yaml:"socks5_proxy_addr"`
+	TLSServerName         string   `json:"tls_server_name" yaml:"tls_server_name"`
+	MinTLSVersion         string   `json:"min_tls_version" yaml:"min_tls_version"`
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// BuilderFromConfig returns Default configured by cfg, validating the whole
+// of cfg and returning every problem found at once. The builder can be
+// refined further before building, for example with hooks that cannot be
+// expressed in configuration.
+func BuilderFromConfig(cfg Config) (ClientBuilder, error) {
+	if err := cfg.validate(); err != nil {
+		return ClientBuilder{}, fmt.Errorf("invalid client config: %w", err)
+	}
+
+	cb := Default
+	if cfg.Timeout > 0 {
+		cb = cb.WithTimeout(time.Duration(cfg.Timeout))
+	}
+
+	if cfg.DisableNewRelic {
+		cb = cb.DisableNewRelic()
+	}
+
+	if cfg.DisableSmartShopHeaders {
+		cb = cb.DisableSmartShopHeaders()
+	}
+
+	for key, value := range cfg.Headers {
+		cb = cb.WithStaticHeader(key, value)
+	}
+
+	if cfg.CircuitBreaker.Disabled {
+		cb = cb.DisableCircuitBreaker()
+	} else {
+		cb = cb.WithCircuitBreakerSettings(circuitbreaker.Settings{
+			Settings: gobreaker.Settings{
+				Name:        cfg.CircuitBreaker.Name,
+				MaxRequests: cfg.CircuitBreaker.MaxRequests,
+				Interval:    time.Duration(cfg.CircuitBreaker.Interval),
+				Timeout:     time.Duration(cfg.CircuitBreaker.Timeout),
+			},
+			SlowCallThreshold:       time.Duration(cfg.CircuitBreaker.SlowCallThreshold),
+			SlowCallRateToTrip:      cfg.CircuitBreaker.SlowCallRateToTrip,
+			SlowCallMinimumRequests: cfg.CircuitBreaker.SlowCallMinimumRequests,
+		})
+	}
+
+	return cb.WithPoolSettings(PoolSettings{
+		MaxIdleConns:          cfg.Pool.MaxIdleConns,
+		MaxIdleConnsPerHost:   cfg.Pool.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       cfg.Pool.MaxConnsPerHost,
+		IdleConnTimeout:       time.Duration(cfg.Pool.IdleConnTimeout),
+		TLSHandshakeTimeout:   time.Duration(cfg.Pool.TLSHandshakeTimeout),
+		ResponseHeaderTimeout: time.Duration(cfg.Pool.ResponseHeaderTimeout),
+		ConnectTimeout:        time.Duration(cfg.Pool.ConnectTimeout),
+		ExpectContinueTimeout: time.Duration(cfg.Pool.ExpectContinueTimeout),
+		ResponseBodyTimeout:   time.Duration(cfg.Pool.ResponseBodyTimeout),
+		ReadIdleTimeout:       time.Duration(cfg.Pool.ReadIdleTimeout),
+		PingTimeout:           time.Duration(cfg.Pool.PingTimeout),
+		ReadBufferSize:        cfg.Pool.ReadBufferSize,
+		WriteBufferSize:       cfg.Pool.WriteBufferSize,
+		DisableKeepAlives:     cfg.Pool.DisableKeepAlives,
+		DisableCompression:    cfg.Pool.DisableCompression,
+		CompressionEncodings:  cfg.Pool.CompressionEncodings,
+		DualStack:             cfg.Pool.DualStack,
+		FallbackDelay:         time.Duration(cfg.Pool.FallbackDelay),
+		UnixSocket:            cfg.Pool.UnixSocket,
+		SOCKS5ProxyAddr:       cfg.Pool.SOCKS5ProxyAddr,
+		TLSServerName:         cfg.Pool.TLSServerName,
+		MinTLSVersion:         tlsVersions[cfg.Pool.MinTLSVersion],
+	}), nil
+}
+
+// NewClientFromConfig builds a client configured by cfg.
+func NewClientFromConfig(cfg Config) (*http.Client, error) {
+	cb, err := BuilderFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return cb.Build(), nil
+}
+
+func (cfg Config) validate() error {
+	var errs []error
+
+	for _, d := range []struct {
+		name  string
+		value Duration
+	}{
+		{"timeout", cfg.Timeout},
+		{"circuit_breaker.interval", cfg.CircuitBreaker.Interval},
+		{"circuit_breaker.timeout", cfg.CircuitBreaker.Timeout},
+		{"circuit_breaker.slow_call_threshold", cfg.CircuitBreaker.SlowCallThreshold},
+		{"pool.idle_conn_timeout", cfg.Pool.IdleConnTimeout},
+		{"pool.tls_handshake_timeout", cfg.Pool.TLSHandshakeTimeout},
+		{"pool.response_header_timeout", cfg.Pool.ResponseHeaderTimeout},
+		{"pool.connect_timeout", cfg.Pool.ConnectTimeout},
+		{"pool.expect_continue_timeout", cfg.Pool.ExpectContinueTimeout},
+		{"pool.response_body_timeout", cfg.Pool.ResponseBodyTimeout},
+		{"pool.read_idle_timeout", cfg.Pool.ReadIdleTimeout},
+		{"pool.ping_timeout", cfg.Pool.PingTimeout},
+	} {
+		if d.value < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative", d.name))
+		}
+	}
+
+	for _, c := range []struct {
+		name  string
+		value int
+	}{
+		{"pool.max_idle_conns", cfg.Pool.MaxIdleConns},
+		{"pool.max_idle_conns_per_host", cfg.Pool.MaxIdleConnsPerHost},
+		{"pool.max_conns_per_host", cfg.Pool.MaxConnsPerHost},
+		{"pool.read_buffer_size", cfg.Pool.ReadBufferSize},
+		{"pool.write_buffer_size", cfg.Pool.WriteBufferSize},
+	} {
+		if c.value < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative", c.name))
+		}
+	}
+
+	if _, ok := tlsVersions[cfg.Pool.MinTLSVersion]; cfg.Pool.MinTLSVersion != "" && !ok {
+		errs = append(errs, fmt.Errorf("pool.min_tls_version %q is not one of 1.0, 1.1, 1.2 or 1.3", cfg.Pool.MinTLSVersion))
+	}
+
+	if rate := cfg.CircuitBreaker.SlowCallRateToTrip; rate < 0 || rate > 1 {
+		errs = append(errs, errors.New("circuit_breaker.slow_call_rate_to_trip must be between 0 and 1"))
+	}
+
+	if _, ok := cfg.Headers[""]; ok {
+		errs = append(errs, errors.New("headers must not have an empty name"))
+	}
+
+	return errors.Join(errs...)
+}
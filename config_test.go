@@ -0,0 +1,145 @@
+package go_http_client_test
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	httpclient "github.com/JSainsburyPLC/danielchurm/go-http-client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.yaml.in/yaml/v3"
+)
+
+var _ = Describe("Config", func() {
+	const configYAML = `
+timeout: 5s
+disable_new_relic: true
+disable_smartshop_headers: true
+headers:
+  X-Api-Version: "2"
+circuit_breaker:
+  name: upstream
+  timeout: 10s
+  slow_call_minimum_requests: 20
+pool:
+  max_idle_conns_per_host: 20
+  idle_conn_timeout: 1m
+`
+
+	It("builds a working client from YAML", func() {
+		var received http.Header
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = r.Header.Clone()
+		}))
+		defer server.Close()
+
+		var cfg httpclient.Config
+		Expect(yaml.Unmarshal([]byte(configYAML), &cfg)).To(Succeed(), "failed to decode config")
+
+		builder, err := httpclient.BuilderFromConfig(cfg)
+		Expect(err).ToNot(HaveOccurred(), "valid config rejected")
+		Expect(builder.Timeout).To(Equal(5 * time.Second))
+		Expect(builder.TransportChain()).To(Equal([]string{"circuitbreaker", "headers", "base"}))
+		Expect(builder.CircuitBreaker.Settings.Name).To(Equal("upstream"))
+		Expect(builder.CircuitBreaker.Settings.SlowCallMinimumRequests).To(Equal(uint32(20)))
+
+		transport := httpclient.BaseTransport(builder)
+		Expect(transport.MaxIdleConnsPerHost).To(Equal(20))
+		Expect(transport.IdleConnTimeout).To(Equal(time.Minute))
+
+		client, err := httpclient.NewClientFromConfig(cfg)
+		Expect(err).ToNot(HaveOccurred())
+
+		resp, err := client.Get(server.URL)
+		Expect(err).ToNot(HaveOccurred(), "request failed")
+		Expect(resp.Body.Close()).To(Succeed())
+		Expect(received.Get("X-Api-Version")).To(Equal("2"), "configured header not sent")
+	})
+
+	It("round-trips through YAML and JSON", func() {
+		var cfg httpclient.Config
+		Expect(yaml.Unmarshal([]byte(configYAML), &cfg)).To(Succeed())
+
+		encoded, err := yaml.Marshal(cfg)
+		Expect(err).ToNot(HaveOccurred())
+		var fromYAML httpclient.Config
+		Expect(yaml.Unmarshal(encoded, &fromYAML)).To(Succeed())
+		Expect(fromYAML).To(Equal(cfg), "YAML round trip changed the config")
+
+		encoded, err = json.Marshal(cfg)
+		Expect(err).ToNot(HaveOccurred())
+		var fromJSON httpclient.Config
+		Expect(json.Unmarshal(encoded, &fromJSON)).To(Succeed())
+		Expect(fromJSON).To(Equal(cfg), "JSON round trip changed the config")
+	})
+
+	It("maps every pool setting and round-trips it", func() {
+		const poolYAML = `
+pool:
+  connect_timeout: 2s
+  expect_continue_timeout: 500ms
+  read_idle_timeout: 30s
+  ping_timeout: 5s
+  read_buffer_size: 65536
+  write_buffer_size: 32768
+  disable_compression: true
+  compression_encodings: [gzip, deflate]
+  dual_stack: true
+  fallback_delay: -1ns
+  socks5_proxy_addr: 127.0.0.1:1080
+  tls_server_name: api.example.com
+  min_tls_version: "1.3"
+`
+		var cfg httpclient.Config
+		Expect(yaml.Unmarshal([]byte(poolYAML), &cfg)).To(Succeed(), "failed to decode config")
+
+		encoded, err := yaml.Marshal(cfg.Pool)
+		Expect(err).ToNot(HaveOccurred())
+		var fromYAML httpclient.PoolConfig
+		Expect(yaml.Unmarshal(encoded, &fromYAML)).To(Succeed())
+		Expect(fromYAML).To(Equal(cfg.Pool), "YAML round trip changed the pool config")
+
+		builder, err := httpclient.BuilderFromConfig(cfg)
+		Expect(err).ToNot(HaveOccurred(), "valid config rejected")
+		Expect(builder.Pool).To(Equal(httpclient.PoolSettings{
+			ConnectTimeout:        2 * time.Second,
+			ExpectContinueTimeout: 500 * time.Millisecond,
+			ReadIdleTimeout:       30 * time.Second,
+			PingTimeout:           5 * time.Second,
+			ReadBufferSize:        64 << 10,
+			WriteBufferSize:       32 << 10,
+			DisableCompression:    true,
+			CompressionEncodings:  []string{"gzip", "deflate"},
+			DualStack:             true,
+			FallbackDelay:         -1,
+			SOCKS5ProxyAddr:       "127.0.0.1:1080",
+			TLSServerName:         "api.example.com",
+			MinTLSVersion:         tls.VersionTLS13,
+		}))
+	})
+
+	It("rejects unknown TLS versions", func() {
+		_, err := httpclient.BuilderFromConfig(httpclient.Config{Pool: httpclient.PoolConfig{MinTLSVersion: "1.4"}})
+		Expect(err).To(MatchError(ContainSubstring("pool.min_tls_version")))
+	})
+
+	It("reports every invalid setting at once", func() {
+		_, err := httpclient.BuilderFromConfig(httpclient.Config{
+			Timeout:        httpclient.Duration(-time.Second),
+			CircuitBreaker: httpclient.CircuitBreakerConfig{SlowCallRateToTrip: 2},
+			Pool:           httpclient.PoolConfig{MaxIdleConns: -1},
+		})
+
+		Expect(err).To(MatchError(ContainSubstring("timeout must not be negative")))
+		Expect(err).To(MatchError(ContainSubstring("slow_call_rate_to_trip must be between 0 and 1")))
+		Expect(err).To(MatchError(ContainSubstring("pool.max_idle_conns must not be negative")))
+	})
+
+	It("rejects durations that cannot be parsed", func() {
+		var cfg httpclient.Config
+		Expect(yaml.Unmarshal([]byte("timeout: soon"), &cfg)).ToNot(Succeed())
+	})
+})
@@ -0,0 +1,98 @@
+package go_http_client
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+)
+
+var decompressors = map[string]func(io.Reader) (io.ReadCloser, error){
+	"gzip": func(r io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(r)
+	},
+	"deflate": zlib.NewReader,
+}
+
+type decompressionTransport struct {
+	wrapped        http.RoundTripper
+	encodings      map[string]bool
+	acceptEncoding string
+}
+
+// newDecompressionTransport negotiates and decompresses the given encodings.
+// Only gzip and deflate are supported; other encodings are ignored.
+func newDecompressionTransport(wrapped http.RoundTripper, encodings []string) http.RoundTripper {
+	t := &decompressionTransport{
+		wrapped:   wrapped,
+		encodings: map[string]bool{},
+	}
+
+	var accepted []string
+	for _, encoding := range encodings {
+		encoding = strings.ToLower(encoding)
+		if _, ok := decompressors[encoding]; ok && !t.encodings[encoding] {
+			t.encodings[encoding] = true
+			accepted = append(accepted, encoding)
+		}
+	}
+	t.acceptEncoding = strings.Join(accepted, ", ")
+
+	return t
+}
+
+// RoundTrip sends the configured Accept-Encoding and transparently
+// decompresses matching responses. Requests that already set
+// Accept-Encoding are left for the caller to handle.
+func (t decompressionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.acceptEncoding == "" || req.Header.Get("Accept-Encoding") != "" {
+		return t.wrapped.RoundTrip(req)
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Accept-Encoding", t.acceptEncoding)
+
+	resp, err := t.wrapped.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	encoding := strings.ToLower(resp.Header.Get("Content-Encoding"))
+	if !t.encodings[encoding] {
+		return resp, nil
+	}
+
+	resp.Body = &decompressReader{body: resp.Body, newReader: decompressors[encoding]}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+
+	return resp, nil
+}
+
+// decompressReader defers creating the decompressor until the first read,
+// so empty bodies such as HEAD responses do not fail.
+type decompressReader struct {
+	body      io.ReadCloser
+	newReader func(io.Reader) (io.ReadCloser, error)
+	reader    io.ReadCloser
+	err       error
+}
+
+func (r *decompressReader) Read(p []byte) (int, error) {
+	if r.reader == nil && r.err == nil {
+		r.reader, r.err = r.newReader(r.body)
+	}
+
+	if r.err != nil {
+		return 0, r.err
+	}
+
+	return r.reader.Read(p)
+}
+
+func (r *decompressReader) Close() error {
+	return r.body.Close()
+}
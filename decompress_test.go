@@ -0,0 +1,91 @@
+package go_http_client_test
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	httpclient "github.com/JSainsburyPLC/danielchurm/go-http-client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Compression Encodings", func() {
+	var (
+		server         *httptest.Server
+		acceptEncoding string
+		client         *http.Client
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			acceptEncoding = r.Header.Get("Accept-Encoding")
+
+			switch {
+			case r.URL.Path == "/br":
+				w.Header().Set("Content-Encoding", "br")
+				_, _ = w.Write([]byte("raw brotli"))
+			case strings.Contains(acceptEncoding, "gzip"):
+				w.Header().Set("Content-Encoding", "gzip")
+				gzipWriter := gzip.NewWriter(w)
+				_, _ = gzipWriter.Write([]byte("hello world"))
+				_ = gzipWriter.Close()
+			default:
+				_, _ = w.Write([]byte("hello world"))
+			}
+		}))
+
+		client = baseBuilder().WithPoolSettings(httpclient.PoolSettings{
+			DisableCompression:   true,
+			CompressionEncodings: []string{"gzip", "zstd"},
+		}).Build()
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("negotiates and decompresses gzip only", func() {
+		resp, err := client.Get(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		defer resp.Body.Close()
+
+		Expect(acceptEncoding).To(Equal("gzip"), "unsupported encodings should not be requested")
+		Expect(resp.Uncompressed).To(BeTrue())
+		Expect(resp.Header.Get("Content-Encoding")).To(BeEmpty())
+
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(body)).To(Equal("hello world"))
+	})
+
+	It("leaves encodings it did not negotiate alone", func() {
+		resp, err := client.Get(server.URL + "/br")
+		Expect(err).ToNot(HaveOccurred())
+		defer resp.Body.Close()
+
+		Expect(resp.Header.Get("Content-Encoding")).To(Equal("br"))
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(body)).To(Equal("raw brotli"))
+	})
+
+	It("leaves requests with their own Accept-Encoding to the caller", func() {
+		req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		Expect(err).ToNot(HaveOccurred())
+		req.Header.Set("Accept-Encoding", "gzip")
+
+		resp, err := client.Do(req)
+		Expect(err).ToNot(HaveOccurred())
+		defer resp.Body.Close()
+
+		Expect(resp.Header.Get("Content-Encoding")).To(Equal("gzip"), "response decompressed for the caller")
+	})
+
+	It("decompresses nothing when compression is left to the transport", func() {
+		chain := baseBuilder().WithPoolSettings(httpclient.PoolSettings{CompressionEncodings: []string{"gzip"}}).TransportChain()
+		Expect(chain).To(Equal([]string{"headers", "base"}))
+	})
+})
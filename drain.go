@@ -0,0 +1,52 @@
+package go_http_client
+
+import (
+	"errors"
+	"net/http"
+	"sync/atomic"
+)
+
+var ErrDraining = errors.New("client is draining")
+
+// Drainer rejects new requests with ErrDraining while draining, leaving
+// requests already in flight to finish, for example during a rolling
+// deploy. The zero value is ready to use and can be shared by several
+// clients to drain them together.
+type Drainer struct {
+	draining atomic.Bool
+}
+
+func (d *Drainer) Drain() {
+	d.draining.Store(true)
+}
+
+func (d *Drainer) Undrain() {
+	d.draining.Store(false)
+}
+
+func (d *Drainer) Draining() bool {
+	return d.draining.Load()
+}
+
+type drainTransport struct {
+	wrapped http.RoundTripper
+	drainer *Drainer
+}
+
+func newDrainTransport(wrapped http.RoundTripper, drainer *Drainer) http.RoundTripper {
+	return &drainTransport{
+		wrapped: wrapped,
+		drainer: drainer,
+	}
+}
+
+func (t drainTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.drainer.Draining() {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+		return nil, ErrDraining
+	}
+
+	return t.wrapped.RoundTrip(req)
+}
@@ -0,0 +1,76 @@
+package go_http_client_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+
+	httpclient "github.com/JSainsburyPLC/danielchurm/go-http-client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Drainer", func() {
+	var (
+		server  *httptest.Server
+		hits    atomic.Int32
+		release chan struct{}
+	)
+
+	BeforeEach(func() {
+		hits.Store(0)
+		release = make(chan struct{})
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits.Add(1)
+			if r.URL.Path == "/slow" {
+				<-release
+			}
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("rejects new requests while draining and resumes after undrain", func() {
+		drainer := &httpclient.Drainer{}
+		client := httpclient.Default.DisableNewRelic().DisableSmartShopHeaders().WithDrainer(drainer).Build()
+
+		drainer.Drain()
+		_, err := client.Get(server.URL)
+		Expect(errors.Is(err, httpclient.ErrDraining)).To(BeTrue(), "request not rejected while draining")
+		Expect(hits.Load()).To(BeZero(), "request reached the server while draining")
+
+		drainer.Undrain()
+		resp, err := client.Get(server.URL)
+		Expect(err).ToNot(HaveOccurred(), "request rejected after undrain")
+		Expect(resp.Body.Close()).To(Succeed())
+		Expect(hits.Load()).To(BeEquivalentTo(1))
+	})
+
+	It("lets requests already in flight finish", func() {
+		drainer := &httpclient.Drainer{}
+		client := baseBuilder().WithDrainer(drainer).Build()
+
+		done := make(chan error)
+		go func() {
+			resp, err := client.Get(server.URL + "/slow")
+			if err == nil {
+				err = resp.Body.Close()
+			}
+			done <- err
+		}()
+
+		Eventually(hits.Load).Should(BeEquivalentTo(1), "in-flight request never started")
+		drainer.Drain()
+		close(release)
+
+		Eventually(done).Should(Receive(BeNil()), "in-flight request did not finish")
+	})
+
+	It("sits outside every other layer", func() {
+		chain := httpclient.Default.WithDrainer(&httpclient.Drainer{}).TransportChain()
+		Expect(chain[0]).To(Equal("drain"))
+	})
+})
@@ -0,0 +1,44 @@
+package go_http_client
+
+import "net/http"
+
+type expectContinueTransport struct {
+	wrapped   http.RoundTripper
+	threshold int64
+}
+
+func newExpectContinueTransport(wrapped http.RoundTripper, threshold int64) http.RoundTripper {
+	return &expectContinueTransport{
+		wrapped:   wrapped,
+		threshold: threshold,
+	}
+}
+
+// RoundTrip asks the server to accept large bodies before they are sent,
+// resending the body once without asking if the server rejects the
+// expectation with a 417 and the body can be replayed. Like net/http, it
+// treats a body with a ContentLength of 0 as of unknown length.
+func (t expectContinueTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body == nil || req.Body == http.NoBody || req.Header.Get("Expect") != "" ||
+		(req.ContentLength > 0 && req.ContentLength <= t.threshold) {
+		return t.wrapped.RoundTrip(req)
+	}
+
+	expecting := req.Clone(req.Context())
+	expecting.Header.Set("Expect", "100-continue")
+
+	resp, err := t.wrapped.RoundTrip(expecting)
+	if err != nil || resp.StatusCode != http.StatusExpectationFailed || req.GetBody == nil {
+		return resp, err
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return resp, nil
+	}
+	_ = resp.Body.Close()
+
+	retry := req.Clone(req.Context())
+	retry.Body = body
+	return t.wrapped.RoundTrip(retry)
+}
@@ -0,0 +1,79 @@
+package go_http_client_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Expect Continue", func() {
+	var (
+		expects []string
+		bodies  []string
+	)
+
+	serve := func(rejectExpectation bool) *httptest.Server {
+		expects, bodies = nil, nil
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			expects = append(expects, r.Header.Get("Expect"))
+			if rejectExpectation && r.Header.Get("Expect") != "" {
+				w.WriteHeader(http.StatusExpectationFailed)
+				return
+			}
+
+			body, _ := io.ReadAll(r.Body)
+			bodies = append(bodies, string(body))
+		}))
+	}
+
+	post := func(url string, body io.Reader) *http.Response {
+		resp, err := baseBuilder().WithExpectContinue(100).Build().Post(url, "text/plain", body)
+		Expect(err).ToNot(HaveOccurred(), "request failed")
+		Expect(resp.Body.Close()).To(Succeed())
+		return resp
+	}
+
+	It("sends the expectation with bodies over the threshold", func() {
+		server := serve(false)
+		defer server.Close()
+
+		large := strings.Repeat("x", 200)
+		post(server.URL, strings.NewReader(large))
+		post(server.URL, strings.NewReader("small"))
+
+		Expect(expects).To(Equal([]string{"100-continue", ""}), "expectation not sent only for the large body")
+		Expect(bodies).To(Equal([]string{large, "small"}))
+	})
+
+	It("sends the expectation with streaming bodies of unknown length", func() {
+		server := serve(false)
+		defer server.Close()
+
+		reader, writer := io.Pipe()
+		go func() {
+			_, _ = writer.Write([]byte("streamed"))
+			_ = writer.Close()
+		}()
+		post(server.URL, reader)
+
+		Expect(expects).To(Equal([]string{"100-continue"}), "expectation not sent for a body of unknown length")
+		Expect(bodies).To(Equal([]string{"streamed"}))
+	})
+
+	It("resends the body without the expectation after a 417", func() {
+		server := serve(true)
+		defer server.Close()
+
+		large := strings.Repeat("x", 200)
+		resp := post(server.URL, bytes.NewReader([]byte(large)))
+
+		Expect(resp.StatusCode).To(Equal(http.StatusOK), "417 not handled")
+		Expect(expects).To(Equal([]string{"100-continue", ""}))
+		Expect(bodies).To(Equal([]string{large}), "body not resent")
+	})
+})
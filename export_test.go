@@ -0,0 +1,36 @@
+package go_http_client
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+)
+
+var NewDialer = newDialer
+
+func BaseTransport(cb ClientBuilder) *http.Transport {
+	return newBaseTransport(cb.Pool)
+}
+
+func HostTransport(cb ClientBuilder, host string) *http.Transport {
+	return newHostPoolTransport(cb.Pool, cb.HostPools).transportFor(&url.URL{Host: host})
+}
+
+// SetSlowRequestLogger replaces how slow requests are logged until the
+// returned func restores it.
+func SetSlowRequestLogger(fn func(req *http.Request, statusCode int, duration time.Duration, err error)) (restore func()) {
+	previous := logSlowRequest
+	logSlowRequest = fn
+	return func() { logSlowRequest = previous }
+}
+
+// BuiltTransport returns the base transport a client built from a chain of
+// just headers and base sends requests for host through.
+func BuiltTransport(client *http.Client, host string) *http.Transport {
+	rt := client.Transport
+	if headers, ok := rt.(*headerTransport); ok {
+		rt = headers.wrapped
+	}
+
+	return rt.(*freshConnectionTransport).transportFor(&url.URL{Host: host})
+}
@@ -0,0 +1,122 @@
+package go_http_client
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var ErrInjectedFault = errors.New("injected fault")
+
+// FaultSettings configures artificial latency and failures for resilience
+// testing. Nothing is injected unless Enabled is set, so the settings can be
+// loaded everywhere and only switched on in environments like staging.
+type FaultSettings struct {
+	Enabled bool
+
+	// LatencyP is the probability, between 0 and 1, that a request is
+	// delayed by Latency before it is sent.
+	LatencyP float64
+	Latency  time.Duration
+
+	// ErrorP is the probability that a request fails with Error, or
+	// ErrInjectedFault if Error is nil, without being sent.
+	ErrorP float64
+	Error  error
+
+	// StatusP is the probability that a request is answered with an empty
+	// response with Status, or 503 if Status is unset, without being sent.
+	StatusP float64
+	Status  int
+
+	// Rand, when set, is the source of randomness, for example seeded to
+	// make tests repeatable. It is only used under a lock.
+	Rand *rand.Rand
+}
+
+type faultInjectionTransport struct {
+	wrapped  http.RoundTripper
+	settings FaultSettings
+	mu       sync.Mutex
+}
+
+func newFaultInjectionTransport(wrapped http.RoundTripper, settings FaultSettings) http.RoundTripper {
+	if settings.Error == nil {
+		settings.Error = ErrInjectedFault
+	}
+
+	if settings.Status == 0 {
+		settings.Status = http.StatusServiceUnavailable
+	}
+
+	return &faultInjectionTransport{
+		wrapped:  wrapped,
+		settings: settings,
+	}
+}
+
+func (t *faultInjectionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.roll(t.settings.LatencyP) {
+		timer := time.NewTimer(t.settings.Latency)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			closeRequestBody(req)
+			return nil, req.Context().Err()
+		}
+	}
+
+	if t.roll(t.settings.ErrorP) {
+		closeRequestBody(req)
+		return nil, t.settings.Error
+	}
+
+	if t.roll(t.settings.StatusP) {
+		closeRequestBody(req)
+		return &http.Response{
+			Status:     fmt.Sprintf("%d %s", t.settings.Status, http.StatusText(t.settings.Status)),
+			StatusCode: t.settings.Status,
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     http.Header{},
+			Body:       http.NoBody,
+			Request:    req,
+		}, nil
+	}
+
+	return t.wrapped.RoundTrip(req)
+}
+
+func (t *faultInjectionTransport) CloseIdleConnections() {
+	if idler, ok := t.wrapped.(closeIdler); ok {
+		idler.CloseIdleConnections()
+	}
+}
+
+// roll reports whether an event with probability p happens, never drawing
+// for a zero probability.
+func (t *faultInjectionTransport) roll(p float64) bool {
+	if p <= 0 {
+		return false
+	}
+
+	if t.settings.Rand == nil {
+		return rand.Float64() < p
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.settings.Rand.Float64() < p
+}
+
+func closeRequestBody(req *http.Request) {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+}
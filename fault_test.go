@@ -0,0 +1,148 @@
+package go_http_client_test
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	httpclient "github.com/JSainsburyPLC/danielchurm/go-http-client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Fault injection", func() {
+	const requests = 1000
+
+	var (
+		server *httptest.Server
+		hits   atomic.Int32
+	)
+
+	BeforeEach(func() {
+		hits.Store(0)
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits.Add(1)
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	seeded := func() *rand.Rand {
+		return rand.New(rand.NewPCG(1, 2))
+	}
+
+	It("fails and answers requests at the configured rates", func() {
+		client := baseBuilder().WithFaultInjection(httpclient.FaultSettings{
+			Enabled: true,
+			ErrorP:  0.2,
+			StatusP: 0.25,
+			Status:  http.StatusServiceUnavailable,
+			Rand:    seeded(),
+		}).Build()
+
+		var errs, unavailable int
+		for range requests {
+			resp, err := client.Get(server.URL)
+			if err != nil {
+				Expect(errors.Is(err, httpclient.ErrInjectedFault)).To(BeTrue(), "unexpected error: %v", err)
+				errs++
+				continue
+			}
+
+			if resp.StatusCode == http.StatusServiceUnavailable {
+				unavailable++
+			}
+			Expect(resp.Body.Close()).To(Succeed())
+		}
+
+		Expect(errs).To(BeNumerically("~", 0.2*requests, 0.05*requests), "error rate off")
+		// Status faults are only rolled for requests that did not error.
+		Expect(unavailable).To(BeNumerically("~", 0.25*0.8*requests, 0.05*requests), "status rate off")
+		Expect(int(hits.Load())).To(Equal(requests-errs-unavailable), "faulted requests reached the server")
+	})
+
+	It("delays requests at the configured rate", func() {
+		client := baseBuilder().WithFaultInjection(httpclient.FaultSettings{
+			Enabled:  true,
+			LatencyP: 0.5,
+			Latency:  time.Millisecond,
+			Rand:     seeded(),
+		}).Build()
+
+		start := time.Now()
+		for range 100 {
+			resp, err := client.Get(server.URL)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.Body.Close()).To(Succeed())
+		}
+		Expect(time.Since(start)).To(BeNumerically(">=", 30*time.Millisecond), "requests not delayed")
+	})
+
+	It("abandons an injected delay once the request is cancelled", func() {
+		client := baseBuilder().WithFaultInjection(httpclient.FaultSettings{
+			Enabled:  true,
+			LatencyP: 1,
+			Latency:  time.Minute,
+		}).Build()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = client.Do(req)
+		Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue(), "delay not cancelled")
+	})
+
+	It("uses the configured error", func() {
+		errChaos := errors.New("chaos")
+		client := baseBuilder().WithFaultInjection(httpclient.FaultSettings{
+			Enabled: true,
+			ErrorP:  1,
+			Error:   errChaos,
+		}).Build()
+
+		_, err := client.Get(server.URL)
+		Expect(errors.Is(err, errChaos)).To(BeTrue())
+	})
+
+	It("answers with a 503 when no status is configured", func() {
+		client := baseBuilder().WithFaultInjection(httpclient.FaultSettings{
+			Enabled: true,
+			StatusP: 1,
+		}).Build()
+
+		resp, err := client.Get(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Body.Close()).To(Succeed())
+		Expect(resp.StatusCode).To(Equal(http.StatusServiceUnavailable))
+		Expect(resp.Status).To(Equal("503 Service Unavailable"))
+	})
+
+	It("injects nothing unless enabled", func() {
+		cb := baseBuilder().WithFaultInjection(httpclient.FaultSettings{ErrorP: 1})
+		Expect(cb.TransportChain()).To(Equal([]string{"headers", "base"}))
+
+		resp, err := cb.Build().Get(server.URL)
+		Expect(err).ToNot(HaveOccurred(), "fault injected while disabled")
+		Expect(resp.Body.Close()).To(Succeed())
+	})
+
+	It("injects nothing with zero probabilities", func() {
+		client := baseBuilder().WithFaultInjection(httpclient.FaultSettings{Enabled: true}).Build()
+
+		for range 50 {
+			resp, err := client.Get(server.URL)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.Body.Close()).To(Succeed())
+		}
+		Expect(hits.Load()).To(BeEquivalentTo(50))
+	})
+})
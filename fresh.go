@@ -0,0 +1,70 @@
+package go_http_client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+type freshConnectionKey struct{}
+
+// WithFreshConnection returns a context whose requests are sent over a newly
+// dialed connection that is closed afterwards, never one from the idle pool,
+// for example to force a new TLS session. Unlike DisableKeepAlives it only
+// affects requests made with the context.
+func WithFreshConnection(ctx context.Context) context.Context {
+	return context.WithValue(ctx, freshConnectionKey{}, true)
+}
+
+func freshConnectionRequested(ctx context.Context) bool {
+	fresh, _ := ctx.Value(freshConnectionKey{}).(bool)
+	return fresh
+}
+
+// freshConnectionTransport sends requests asking for a fresh connection
+// through a copy of the pooled transport they would otherwise use, with
+// keep-alives disabled so it never holds idle connections.
+type freshConnectionTransport struct {
+	pooled       http.RoundTripper
+	transportFor func(*url.URL) *http.Transport
+
+	mu    sync.Mutex
+	fresh map[*http.Transport]*http.Transport
+}
+
+func newFreshConnectionTransport(pooled http.RoundTripper, transportFor func(*url.URL) *http.Transport) *freshConnectionTransport {
+	return &freshConnectionTransport{
+		pooled:       pooled,
+		transportFor: transportFor,
+		fresh:        make(map[*http.Transport]*http.Transport),
+	}
+}
+
+func (t *freshConnectionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !freshConnectionRequested(req.Context()) {
+		return t.pooled.RoundTrip(req)
+	}
+
+	return t.freshTransport(t.transportFor(req.URL)).RoundTrip(req)
+}
+
+func (t *freshConnectionTransport) freshTransport(pooled *http.Transport) *http.Transport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	fresh, ok := t.fresh[pooled]
+	if !ok {
+		fresh = pooled.Clone()
+		fresh.DisableKeepAlives = true
+		t.fresh[pooled] = fresh
+	}
+
+	return fresh
+}
+
+func (t *freshConnectionTransport) CloseIdleConnections() {
+	if idler, ok := t.pooled.(closeIdler); ok {
+		idler.CloseIdleConnections()
+	}
+}
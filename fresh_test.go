@@ -0,0 +1,67 @@
+package go_http_client_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+
+	httpclient "github.com/JSainsburyPLC/danielchurm/go-http-client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WithFreshConnection", func() {
+	var (
+		server *httptest.Server
+		dials  atomic.Int32
+	)
+
+	BeforeEach(func() {
+		dials.Store(0)
+		server = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+			if state == http.StateNew {
+				dials.Add(1)
+			}
+		}
+		server.Start()
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	get := func(client *http.Client, ctx context.Context) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		resp, err := client.Do(req)
+		Expect(err).ToNot(HaveOccurred(), "request failed")
+		Expect(resp.Body.Close()).To(Succeed())
+	}
+
+	It("dials a new connection instead of reusing an idle one", func() {
+		client := baseBuilder().Build()
+
+		get(client, context.Background())
+		Expect(dials.Load()).To(BeEquivalentTo(1))
+
+		get(client, httpclient.WithFreshConnection(context.Background()))
+		Expect(dials.Load()).To(BeEquivalentTo(2), "fresh connection request reused an idle connection")
+
+		get(client, context.Background())
+		Expect(dials.Load()).To(BeEquivalentTo(2), "pooled request did not reuse the idle connection")
+	})
+
+	It("dials a new connection for hosts with their own pool", func() {
+		client := baseBuilder().WithPerHostPool(map[string]httpclient.PoolSettings{
+			server.Listener.Addr().String(): {MaxIdleConnsPerHost: 1},
+		}, httpclient.PoolSettings{}).Build()
+
+		get(client, context.Background())
+		get(client, httpclient.WithFreshConnection(context.Background()))
+		Expect(dials.Load()).To(BeEquivalentTo(2), "fresh connection request reused an idle connection")
+	})
+})
@@ -0,0 +1,77 @@
+package go_http_client
+
+import (
+	"context"
+	"net/http"
+)
+
+type requestHeadersKey struct{}
+
+// WithRequestHeaders returns a context carrying headers to add to any
+// request made with it, merged with headers from earlier calls on the same
+// context. Headers set on the request itself take precedence, followed by
+// headers from the context and then the builder's static headers.
+func WithRequestHeaders(ctx context.Context, headers http.Header) context.Context {
+	merged := requestHeaders(ctx).Clone()
+	if merged == nil {
+		merged = http.Header{}
+	}
+
+	for key, values := range headers {
+		merged[http.CanonicalHeaderKey(key)] = append([]string(nil), values...)
+	}
+
+	return context.WithValue(ctx, requestHeadersKey{}, merged)
+}
+
+func requestHeaders(ctx context.Context) http.Header {
+	headers, _ := ctx.Value(requestHeadersKey{}).(http.Header)
+	return headers
+}
+
+type defaultHeadersKey struct{}
+
+// withDefaultHeaders returns a context carrying headers to add to a request
+// only when the request, the context headers and the static headers all
+// leave them unset.
+func withDefaultHeaders(ctx context.Context, headers http.Header) context.Context {
+	return context.WithValue(ctx, defaultHeadersKey{}, headers)
+}
+
+func defaultHeaders(ctx context.Context) http.Header {
+	headers, _ := ctx.Value(defaultHeadersKey{}).(http.Header)
+	return headers
+}
+
+type headerTransport struct {
+	wrapped http.RoundTripper
+	headers http.Header
+}
+
+func newHeaderTransport(wrapped http.RoundTripper, headers http.Header) http.RoundTripper {
+	return &headerTransport{
+		wrapped: wrapped,
+		headers: headers,
+	}
+}
+
+// RoundTrip adds the context, static and default headers to a copy of the
+// request.
+func (t headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	contextHeaders := requestHeaders(req.Context())
+	fallbackHeaders := defaultHeaders(req.Context())
+	if len(contextHeaders) == 0 && len(t.headers) == 0 && len(fallbackHeaders) == 0 {
+		return t.wrapped.RoundTrip(req)
+	}
+
+	req = req.Clone(req.Context())
+	for _, headers := range []http.Header{contextHeaders, t.headers, fallbackHeaders} {
+		for key, values := range headers {
+			if _, ok := req.Header[key]; !ok {
+				req.Header[key] = values
+			}
+		}
+	}
+
+	return t.wrapped.RoundTrip(req)
+}
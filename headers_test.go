@@ -0,0 +1,145 @@
+package go_http_client_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	httpclient "github.com/JSainsburyPLC/danielchurm/go-http-client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Headers", func() {
+	var (
+		server   *httptest.Server
+		received http.Header
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = r.Header.Clone()
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	getWithContext := func(ctx context.Context, client *http.Client, header http.Header) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		Expect(err).ToNot(HaveOccurred())
+		for key, values := range header {
+			req.Header[key] = values
+		}
+
+		resp, err := client.Do(req)
+		Expect(err).ToNot(HaveOccurred(), "request failed")
+		Expect(resp.Body.Close()).To(Succeed())
+	}
+
+	get := func(client *http.Client, header http.Header) {
+		getWithContext(context.Background(), client, header)
+	}
+
+	It("merges repeated WithHeaders calls", func() {
+		client := baseBuilder().
+			WithHeaders(http.Header{"X-First": {"one"}, "X-Shared": {"first"}}).
+			WithHeaders(http.Header{"x-second": {"two"}, "X-Shared": {"second"}}).
+			Build()
+
+		get(client, nil)
+		Expect(received.Get("X-First")).To(Equal("one"), "first headers lost")
+		Expect(received.Get("X-Second")).To(Equal("two"), "second headers not applied")
+		Expect(received.Values("X-Shared")).To(Equal([]string{"second"}), "last call did not win")
+	})
+
+	It("adds single static headers", func() {
+		client := baseBuilder().
+			WithStaticHeader("X-Api-Version", "2").
+			WithHeaders(http.Header{"X-Other": {"value"}}).
+			Build()
+
+		get(client, nil)
+		Expect(received.Get("X-Api-Version")).To(Equal("2"), "static header not applied")
+		Expect(received.Get("X-Other")).To(Equal("value"), "headers not merged")
+	})
+
+	It("leaves headers set on the request alone", func() {
+		client := baseBuilder().WithStaticHeader("X-Api-Version", "2").Build()
+
+		get(client, http.Header{"X-Api-Version": {"3"}})
+		Expect(received.Values("X-Api-Version")).To(Equal([]string{"3"}), "request header overwritten")
+	})
+
+	It("does not share headers between builders", func() {
+		base := baseBuilder().WithStaticHeader("X-Base", "1")
+		_ = base.WithStaticHeader("X-Derived", "1")
+
+		get(base.Build(), nil)
+		Expect(received.Get("X-Derived")).To(BeEmpty(), "derived builder modified the base")
+	})
+
+	It("applies headers from the request context", func() {
+		ctx := httpclient.WithRequestHeaders(context.Background(), http.Header{"X-Tenant": {"acme"}})
+		ctx = httpclient.WithRequestHeaders(ctx, http.Header{"x-trace": {"abc"}})
+
+		getWithContext(ctx, baseBuilder().Build(), nil)
+		Expect(received.Get("X-Tenant")).To(Equal("acme"), "context header not applied")
+		Expect(received.Get("X-Trace")).To(Equal("abc"), "context headers not merged")
+	})
+
+	It("does not leak context headers across requests", func() {
+		client := baseBuilder().Build()
+
+		getWithContext(httpclient.WithRequestHeaders(context.Background(), http.Header{"X-Tenant": {"acme"}}), client, nil)
+		Expect(received.Get("X-Tenant")).To(Equal("acme"))
+
+		get(client, nil)
+		Expect(received.Get("X-Tenant")).To(BeEmpty(), "context header leaked into the next request")
+	})
+
+	It("ranks request headers over context headers over static headers", func() {
+		client := baseBuilder().
+			WithHeaders(http.Header{"X-Static": {"static"}, "X-Context": {"static"}, "X-Request": {"static"}}).
+			Build()
+		ctx := httpclient.WithRequestHeaders(context.Background(), http.Header{"X-Context": {"context"}, "X-Request": {"context"}})
+
+		getWithContext(ctx, client, http.Header{"X-Request": {"request"}})
+		Expect(received.Get("X-Static")).To(Equal("static"))
+		Expect(received.Values("X-Context")).To(Equal([]string{"context"}))
+		Expect(received.Values("X-Request")).To(Equal([]string{"request"}))
+	})
+
+	It("defaults the Accept header", func() {
+		client := baseBuilder().WithDefaultAccept("application/json").Build()
+
+		get(client, nil)
+		Expect(received.Get("Accept")).To(Equal("application/json"), "default Accept not sent")
+
+		get(client, http.Header{"Accept": {"application/xml"}})
+		Expect(received.Values("Accept")).To(Equal([]string{"application/xml"}), "request Accept overwritten")
+	})
+
+	It("collapses duplicate values and canonicalizes keys when normalizing", func() {
+		recorder := &httpclient.Recorder{}
+		client := baseBuilder().
+			WithStaticHeader("X-Static", "1").
+			WithHeaderNormalization().
+			WithRecorder(recorder).
+			Build()
+
+		get(client, http.Header{
+			"x-custom": {"a", "b"},
+			"X-Custom": {"a"},
+			"X-Repeat": {"same", "same"},
+		})
+
+		sent := recorder.Requests()[0].Header
+		Expect(sent).ToNot(HaveKey("x-custom"), "non-canonical key sent")
+		Expect(sent["X-Custom"]).To(ConsistOf("a", "b"), "values of keys differing in case not merged")
+		Expect(sent["X-Repeat"]).To(Equal([]string{"same"}), "duplicate value not removed")
+		Expect(sent.Get("X-Static")).To(Equal("1"))
+		Expect(received.Values("X-Custom")).To(ConsistOf("a", "b"))
+	})
+})
@@ -0,0 +1,21 @@
+package go_http_client
+
+import "net/http"
+
+type hostOverrideTransport struct {
+	wrapped http.RoundTripper
+	host    string
+}
+
+func newHostOverrideTransport(wrapped http.RoundTripper, host string) http.RoundTripper {
+	return &hostOverrideTransport{
+		wrapped: wrapped,
+		host:    host,
+	}
+}
+
+func (t hostOverrideTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Host = t.host
+	return t.wrapped.RoundTrip(req)
+}
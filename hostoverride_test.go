@@ -0,0 +1,50 @@
+package go_http_client_test
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+
+	httpclient "github.com/JSainsburyPLC/danielchurm/go-http-client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Host Override", func() {
+	It("sends the overridden Host header and SNI", func() {
+		var receivedHost, serverName string
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			receivedHost = r.Host
+		}))
+		server.TLS = &tls.Config{
+			GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+				serverName = hello.ServerName
+				return nil, nil
+			},
+		}
+		server.StartTLS()
+		defer server.Close()
+
+		client := baseBuilder().
+			WithPoolSettings(httpclient.PoolSettings{
+				TLSClientConfig: server.Client().Transport.(*http.Transport).TLSClientConfig,
+			}).
+			WithHostOverride("api.example.com:8443").
+			Build()
+
+		// the URL addresses the server by IP
+		resp, err := client.Get(server.URL)
+		Expect(err).ToNot(HaveOccurred(), "request failed")
+		Expect(resp.Body.Close()).To(Succeed())
+
+		Expect(receivedHost).To(Equal("api.example.com:8443"), "Host header not overridden")
+		Expect(serverName).To(Equal("api.example.com"), "SNI not overridden")
+	})
+
+	It("leaves the TLS configuration alone by default", func() {
+		transport := httpclient.BaseTransport(baseBuilder())
+		if transport.TLSClientConfig != nil {
+			Expect(transport.TLSClientConfig.ServerName).To(BeEmpty(), "server name set by default")
+		}
+	})
+})
@@ -0,0 +1,50 @@
+package go_http_client
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// hostPoolTransport routes each request to a base transport of its own for
+// hosts with dedicated pool settings, and to a shared default otherwise.
+type hostPoolTransport struct {
+	hosts       map[string]*http.Transport
+	defaultPool *http.Transport
+}
+
+func newHostPoolTransport(defaultPool PoolSettings, hostPools map[string]PoolSettings) *hostPoolTransport {
+	hosts := make(map[string]*http.Transport, len(hostPools))
+	for host, settings := range hostPools {
+		hosts[host] = newBaseTransport(defaultPool.merge(settings))
+	}
+
+	return &hostPoolTransport{
+		hosts:       hosts,
+		defaultPool: newBaseTransport(defaultPool),
+	}
+}
+
+// transportFor matches the URL's host first with its port, then without.
+func (t *hostPoolTransport) transportFor(u *url.URL) *http.Transport {
+	if transport, ok := t.hosts[u.Host]; ok {
+		return transport
+	}
+
+	if transport, ok := t.hosts[u.Hostname()]; ok {
+		return transport
+	}
+
+	return t.defaultPool
+}
+
+func (t *hostPoolTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.transportFor(req.URL).RoundTrip(req)
+}
+
+func (t *hostPoolTransport) CloseIdleConnections() {
+	for _, transport := range t.hosts {
+		transport.CloseIdleConnections()
+	}
+
+	t.defaultPool.CloseIdleConnections()
+}
@@ -0,0 +1,60 @@
+package go_http_client_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	httpclient "github.com/JSainsburyPLC/danielchurm/go-http-client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Per-host Pools", func() {
+	builder := func() httpclient.ClientBuilder {
+		return baseBuilder().WithPerHostPool(
+			map[string]httpclient.PoolSettings{
+				"internal.example.com":     {MaxIdleConnsPerHost: 100},
+				"partner.example.com:8443": {MaxIdleConnsPerHost: 2},
+			},
+			httpclient.PoolSettings{MaxIdleConnsPerHost: 10, IdleConnTimeout: time.Minute},
+		)
+	}
+
+	It("uses the host-specific idle connection limits", func() {
+		cb := builder()
+
+		internal := httpclient.HostTransport(cb, "internal.example.com:443")
+		Expect(internal.MaxIdleConnsPerHost).To(Equal(100), "host matched without its port not used")
+		Expect(internal.IdleConnTimeout).To(Equal(time.Minute), "default pool settings not inherited")
+
+		partner := httpclient.HostTransport(cb, "partner.example.com:8443")
+		Expect(partner.MaxIdleConnsPerHost).To(Equal(2), "host matched with its port not used")
+
+		other := httpclient.HostTransport(cb, "other.example.com")
+		Expect(other.MaxIdleConnsPerHost).To(Equal(10), "default pool not used for other hosts")
+	})
+
+	It("sends requests through the pool for their host", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("ok"))
+		}))
+		defer server.Close()
+
+		host := strings.TrimPrefix(server.URL, "http://")
+		client := baseBuilder().WithPerHostPool(
+			map[string]httpclient.PoolSettings{host: {MaxIdleConnsPerHost: 1}},
+			httpclient.PoolSettings{},
+		).Build()
+
+		resp, err := client.Get(server.URL)
+		Expect(err).ToNot(HaveOccurred(), "request failed")
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(body)).To(Equal("ok"))
+	})
+})
@@ -0,0 +1,55 @@
+package go_http_client
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+type closeIdler interface {
+	CloseIdleConnections()
+}
+
+// idleReleaseTransport closes the idle connections of the transport it wraps
+// once no request has started or finished for maxIdleTime. It uses a timer
+// rearmed by every request rather than a background goroutine, so a client
+// that is never used again holds nothing once the timer has fired.
+type idleReleaseTransport struct {
+	wrapped     http.RoundTripper
+	maxIdleTime time.Duration
+
+	mu    sync.Mutex
+	timer *time.Timer
+}
+
+func newIdleReleaseTransport(wrapped http.RoundTripper, maxIdleTime time.Duration) http.RoundTripper {
+	return &idleReleaseTransport{
+		wrapped:     wrapped,
+		maxIdleTime: maxIdleTime,
+	}
+}
+
+func (t *idleReleaseTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.touch()
+	defer t.touch()
+
+	return t.wrapped.RoundTrip(req)
+}
+
+func (t *idleReleaseTransport) touch() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.timer == nil {
+		t.timer = time.AfterFunc(t.maxIdleTime, t.CloseIdleConnections)
+		return
+	}
+
+	t.timer.Reset(t.maxIdleTime)
+}
+
+func (t *idleReleaseTransport) CloseIdleConnections() {
+	if idler, ok := t.wrapped.(closeIdler); ok {
+		idler.CloseIdleConnections()
+	}
+}
@@ -0,0 +1,98 @@
+package go_http_client_test
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	httpclient "github.com/JSainsburyPLC/danielchurm/go-http-client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Max Idle Time", func() {
+	var (
+		server *httptest.Server
+		opened atomic.Int32
+		closed atomic.Int32
+	)
+
+	BeforeEach(func() {
+		opened.Store(0)
+		closed.Store(0)
+		server = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+			switch state {
+			case http.StateNew:
+				opened.Add(1)
+			case http.StateClosed:
+				closed.Add(1)
+			}
+		}
+		server.Start()
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	get := func(client *http.Client) {
+		resp, err := client.Get(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Body.Close()).To(Succeed())
+	}
+
+	It("closes idle connections after a period of inactivity", func() {
+		client := baseBuilder().WithMaxIdleTime(50 * time.Millisecond).Build()
+
+		get(client)
+		Expect(opened.Load()).To(BeEquivalentTo(1))
+
+		Eventually(closed.Load, "1s").Should(BeEquivalentTo(1), "idle connection not closed")
+
+		get(client)
+		Expect(opened.Load()).To(BeEquivalentTo(2), "closed connection reused")
+	})
+
+	It("keeps connections while the client is active", func() {
+		client := baseBuilder().WithMaxIdleTime(200 * time.Millisecond).Build()
+
+		for range 5 {
+			get(client)
+			time.Sleep(20 * time.Millisecond)
+		}
+
+		Expect(opened.Load()).To(BeEquivalentTo(1), "connection not reused while active")
+		Expect(closed.Load()).To(BeZero(), "connection closed while active")
+	})
+
+	It("releases the connections of every host pool", func() {
+		client := baseBuilder().
+			WithPerHostPool(map[string]httpclient.PoolSettings{"other.example.com": {}}, httpclient.PoolSettings{}).
+			WithMaxIdleTime(50 * time.Millisecond).
+			Build()
+
+		get(client)
+		Eventually(closed.Load, "1s").Should(BeEquivalentTo(1), "idle connection of the default pool not closed")
+	})
+
+	It("releases idle connections through the minimum TLS version layer", func() {
+		client := baseBuilder().WithMinTLSVersion(tls.VersionTLS12).WithMaxIdleTime(50 * time.Millisecond).Build()
+
+		get(client)
+		Eventually(closed.Load, "1s").Should(BeEquivalentTo(1), "idle connection not closed")
+	})
+
+	It("releases idle connections through the fault injection layer", func() {
+		client := baseBuilder().
+			WithFaultInjection(httpclient.FaultSettings{Enabled: true}).
+			WithMaxIdleTime(50 * time.Millisecond).
+			Build()
+
+		get(client)
+		Eventually(closed.Load, "1s").Should(BeEquivalentTo(1), "idle connection not closed")
+	})
+})
@@ -0,0 +1,134 @@
+package go_http_client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// JSONContentTypes are the content types DoJSON accepts, matching any
+// Content-Type header that contains one of them.
+var JSONContentTypes = []string{"application/json"}
+
+var ErrUnexpectedContentType = errors.New("unexpected content type")
+
+const contentTypeSnippetBytes = 200
+
+// ContentTypeError is returned by DoJSON when a response is not JSON, for
+// example an HTML error page served with a 200 status. It matches
+// ErrUnexpectedContentType with errors.Is.
+type ContentTypeError struct {
+	ContentType string
+	Snippet     string
+}
+
+func (e *ContentTypeError) Error() string {
+	return fmt.Sprintf("unexpected content type %q: %s", e.ContentType, e.Snippet)
+}
+
+func (e *ContentTypeError) Is(target error) bool {
+	return target == ErrUnexpectedContentType
+}
+
+// JSONOption configures a single DoJSON or GetAllPages call.
+type JSONOption func(*jsonOptions)
+
+type jsonOptions struct {
+	successStatusCodes []int
+}
+
+// WithSuccessStatusCodes makes DoJSON and GetAllPages treat codes as success
+// as well as any 2XX status, for upstreams that report success with another
+// status.
+func WithSuccessStatusCodes(codes ...int) JSONOption {
+	return func(o *jsonOptions) {
+		o.successStatusCodes = append(o.successStatusCodes, codes...)
+	}
+}
+
+func (o jsonOptions) success(statusCode int) bool {
+	return (statusCode >= 200 && statusCode <= 299) || slices.Contains(o.successStatusCodes, statusCode)
+}
+
+// Response holds what DoJSON read from a response alongside the value it
+// decoded, for callers that also need the status or headers, such as
+// pagination links.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// DoJSON sends req with client and decodes a 2XX response body as JSON into
+// out. Any other status returns the response along with a *StatusError, and
+// a response that is not one of the JSONContentTypes returns it along with a
+// *ContentTypeError, leaving the body undecoded. A successful response with
+// an empty body, such as a 204, leaves out untouched. Requests are sent with
+// "Accept: application/json" unless the request, WithRequestHeaders or the
+// builder's static headers set another Accept.
+func DoJSON(client *http.Client, req *http.Request, out any, opts ...JSONOption) (*Response, error) {
+	var options jsonOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if req.Header.Get("Accept") == "" {
+		req = req.WithContext(withDefaultHeaders(req.Context(), http.Header{"Accept": {"application/json"}}))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := BodyBytes(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &Response{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+	}
+
+	if !options.success(resp.StatusCode) {
+		return response, &StatusError{StatusCode: resp.StatusCode}
+	}
+
+	if len(body) == 0 {
+		return response, nil
+	}
+
+	if !isJSONContentType(resp.Header.Get("Content-Type")) {
+		snippet := body
+		if len(snippet) > contentTypeSnippetBytes {
+			snippet = snippet[:contentTypeSnippetBytes]
+		}
+
+		return response, &ContentTypeError{
+			ContentType: resp.Header.Get("Content-Type"),
+			Snippet:     string(snippet),
+		}
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return response, fmt.Errorf("failed to decode response body: %w", err)
+	}
+
+	return response, nil
+}
+
+func isJSONContentType(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	for _, jsonType := range JSONContentTypes {
+		if strings.Contains(contentType, strings.ToLower(jsonType)) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,183 @@
+package go_http_client_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	httpclient "github.com/JSainsburyPLC/danielchurm/go-http-client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DoJSON", func() {
+	type item struct {
+		Name string `json:"name"`
+	}
+
+	var server *httptest.Server
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/html" {
+				w.Header().Set("Content-Type", "text/html; charset=utf-8")
+				_, _ = w.Write([]byte("<html><body>Service unavailable</body></html>"))
+				return
+			}
+
+			if r.URL.Path == "/accept" {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = fmt.Fprintf(w, `{"name":%q}`, r.Header.Get("Accept"))
+				return
+			}
+
+			if r.URL.Path == "/deleted" {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			if r.URL.Path == "/conflict" {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusConflict)
+				_, _ = w.Write([]byte(`{"name":"existing"}`))
+				return
+			}
+
+			if r.URL.Path == "/multi" {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusMultiStatus)
+				_, _ = w.Write([]byte(`{"name":"partial"}`))
+				return
+			}
+
+			if r.URL.Path == "/missing" {
+				http.Error(w, "not found", http.StatusNotFound)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.Header().Set("X-Next-Page", "2")
+			_, _ = w.Write([]byte(`{"name":"widget"}`))
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("decodes the body and exposes the status and headers", func() {
+		req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		var out item
+		resp, err := httpclient.DoJSON(baseBuilder().Build(), req, &out)
+		Expect(err).ToNot(HaveOccurred(), "request failed")
+
+		Expect(out.Name).To(Equal("widget"), "body not decoded")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(resp.Header.Get("X-Next-Page")).To(Equal("2"), "custom header not exposed")
+		Expect(string(resp.Body)).To(Equal(`{"name":"widget"}`), "raw body not exposed")
+	})
+
+	It("returns a status error for a non-2XX response", func() {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/missing", nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		var out item
+		resp, err := httpclient.DoJSON(baseBuilder().Build(), req, &out)
+
+		var statusErr *httpclient.StatusError
+		Expect(err).To(BeAssignableToTypeOf(statusErr))
+		Expect(err.(*httpclient.StatusError).StatusCode).To(Equal(http.StatusNotFound))
+		Expect(resp.StatusCode).To(Equal(http.StatusNotFound), "response not returned with the error")
+		Expect(out.Name).To(BeEmpty(), "error body decoded")
+	})
+
+	It("rejects a successful response that is not JSON", func() {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/html", nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		var out item
+		_, err = httpclient.DoJSON(baseBuilder().Build(), req, &out)
+		Expect(errors.Is(err, httpclient.ErrUnexpectedContentType)).To(BeTrue(), "HTML response not rejected")
+
+		var contentTypeErr *httpclient.ContentTypeError
+		Expect(errors.As(err, &contentTypeErr)).To(BeTrue())
+		Expect(contentTypeErr.ContentType).To(Equal("text/html; charset=utf-8"))
+		Expect(contentTypeErr.Snippet).To(ContainSubstring("Service unavailable"), "body snippet missing")
+	})
+
+	It("treats a 204 with no body as success without decoding", func() {
+		req, err := http.NewRequest(http.MethodDelete, server.URL+"/deleted", nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		out := item{Name: "unchanged"}
+		resp, err := httpclient.DoJSON(baseBuilder().Build(), req, &out)
+		Expect(err).ToNot(HaveOccurred(), "empty 204 rejected")
+		Expect(resp.StatusCode).To(Equal(http.StatusNoContent))
+		Expect(out.Name).To(Equal("unchanged"), "empty body decoded")
+	})
+
+	It("decodes a 207 multi-status response", func() {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/multi", nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		var out item
+		resp, err := httpclient.DoJSON(baseBuilder().Build(), req, &out)
+		Expect(err).ToNot(HaveOccurred(), "207 rejected")
+		Expect(resp.StatusCode).To(Equal(http.StatusMultiStatus))
+		Expect(out.Name).To(Equal("partial"))
+	})
+
+	It("decodes other statuses configured as success", func() {
+		req, err := http.NewRequest(http.MethodPut, server.URL+"/conflict", nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		var out item
+		_, err = httpclient.DoJSON(baseBuilder().Build(), req, &out, httpclient.WithSuccessStatusCodes(http.StatusConflict))
+		Expect(err).ToNot(HaveOccurred(), "configured success status rejected")
+		Expect(out.Name).To(Equal("existing"))
+
+		_, err = httpclient.DoJSON(baseBuilder().Build(), req, &out)
+		Expect(err).To(BeAssignableToTypeOf(&httpclient.StatusError{}), "unconfigured status accepted")
+	})
+
+	It("asks for JSON when the request has no Accept header", func() {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/accept", nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		var out item
+		_, err = httpclient.DoJSON(baseBuilder().Build(), req, &out)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.Name).To(Equal("application/json"), "default Accept not sent")
+		Expect(req.Header.Get("Accept")).To(BeEmpty(), "caller's request modified")
+	})
+
+	It("keeps an Accept header set on the request", func() {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/accept", nil)
+		Expect(err).ToNot(HaveOccurred())
+		req.Header.Set("Accept", "application/vnd.api+json")
+
+		var out item
+		_, err = httpclient.DoJSON(baseBuilder().Build(), req, &out)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.Name).To(Equal("application/vnd.api+json"), "request Accept replaced")
+	})
+
+	It("keeps an Accept header from the builder or the context", func() {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/accept", nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		var out item
+		_, err = httpclient.DoJSON(baseBuilder().WithDefaultAccept("application/vnd.x+json").Build(), req, &out)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.Name).To(Equal("application/vnd.x+json"), "builder Accept replaced")
+
+		ctx := httpclient.WithRequestHeaders(context.Background(), http.Header{"Accept": {"application/vnd.ctx+json"}})
+		_, err = httpclient.DoJSON(baseBuilder().Build(), req.WithContext(ctx), &out)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.Name).To(Equal("application/vnd.ctx+json"), "context Accept replaced")
+	})
+})
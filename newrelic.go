@@ -0,0 +1,40 @@
+package go_http_client
+
+import (
+	"net/http"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+type newRelicTransport struct {
+	wrapped    http.RoundTripper
+	attributes func(*http.Request) map[string]any
+}
+
+// newNewRelicTransport instruments requests the same way as
+// newrelic.NewRoundTripper, additionally adding the attributes returned for
+// each request to its external segment.
+func newNewRelicTransport(wrapped http.RoundTripper, attributes func(*http.Request) map[string]any) http.RoundTripper {
+	return &newRelicTransport{
+		wrapped:    wrapped,
+		attributes: attributes,
+	}
+}
+
+func (t newRelicTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// StartExternalSegment adds distributed tracing headers, and a round
+	// tripper must not modify the request it was given.
+	req = req.Clone(req.Context())
+
+	segment := newrelic.StartExternalSegment(nil, req)
+	for key, value := range t.attributes(req) {
+		segment.AddAttribute(key, value)
+	}
+
+	resp, err := t.wrapped.RoundTrip(req)
+
+	segment.Response = resp
+	segment.End()
+
+	return resp, err
+}
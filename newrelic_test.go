@@ -0,0 +1,45 @@
+package go_http_client_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("New Relic Attributes", func() {
+	It("derives segment attributes from each request", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+		defer server.Close()
+
+		app, err := newrelic.NewApplication(newrelic.ConfigAppName("go-http-client"), newrelic.ConfigEnabled(false))
+		Expect(err).ToNot(HaveOccurred())
+
+		txn := app.StartTransaction("test")
+		defer txn.End()
+
+		var tenants []string
+		client := baseBuilder().WithNewRelicAttributes(func(req *http.Request) map[string]any {
+			tenants = append(tenants, req.Header.Get("X-Tenant"))
+			return map[string]any{"tenant": req.Header.Get("X-Tenant")}
+		}).Build()
+
+		req, err := http.NewRequestWithContext(newrelic.NewContext(context.Background(), txn), http.MethodGet, server.URL, nil)
+		Expect(err).ToNot(HaveOccurred())
+		req.Header.Set("X-Tenant", "acme")
+
+		resp, err := client.Do(req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Body.Close()).To(Succeed())
+
+		Expect(tenants).To(Equal([]string{"acme"}), "attributes not derived from the request")
+	})
+
+	It("replaces the default New Relic layer", func() {
+		chain := baseBuilder().WithNewRelicAttributes(func(*http.Request) map[string]any { return nil }).TransportChain()
+		Expect(chain).To(Equal([]string{"newrelic", "headers", "base"}))
+	})
+})
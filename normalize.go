@@ -0,0 +1,34 @@
+package go_http_client
+
+import (
+	"net/http"
+	"slices"
+)
+
+type headerNormalizationTransport struct {
+	wrapped http.RoundTripper
+}
+
+func newHeaderNormalizationTransport(wrapped http.RoundTripper) http.RoundTripper {
+	return &headerNormalizationTransport{wrapped: wrapped}
+}
+
+// RoundTrip sends a copy of the request whose header keys are canonical,
+// merging the values of keys that only differed in case, and in which each
+// value appears at most once per key.
+func (t headerNormalizationTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+
+	normalized := make(http.Header, len(req.Header))
+	for key, values := range req.Header {
+		canonical := http.CanonicalHeaderKey(key)
+		for _, value := range values {
+			if !slices.Contains(normalized[canonical], value) {
+				normalized[canonical] = append(normalized[canonical], value)
+			}
+		}
+	}
+
+	req.Header = normalized
+	return t.wrapped.RoundTrip(req)
+}
@@ -0,0 +1,116 @@
+package go_http_client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// GetAllPages fetches url with client and calls each with the response,
+// then follows the RFC 8288 Link header with rel="next" until a page has
+// none. Every page goes through the client's transport chain, so the
+// circuit breaker applies per page. Pages with a non-2XX status, other than
+// those given with WithSuccessStatusCodes, stop the walk with a *StatusError
+// before each is called, as does an error from each itself. The body is
+// closed once each returns.
+func GetAllPages(ctx context.Context, client *http.Client, url string, each func(*http.Response) error, opts ...JSONOption) error {
+	var options jsonOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	visited := make(map[string]bool)
+
+	for next := url; next != ""; {
+		if visited[next] {
+			return fmt.Errorf("pagination loops back to %s", next)
+		}
+		visited[next] = true
+
+		var err error
+		next, err = getPage(ctx, client, next, each, options)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getPage fetches one page and returns the URL of the next, if any.
+func getPage(ctx context.Context, client *http.Client, pageURL string, each func(*http.Response) error, options jsonOptions) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if !options.success(resp.StatusCode) {
+		return "", &StatusError{StatusCode: resp.StatusCode}
+	}
+
+	if err := each(resp); err != nil {
+		return "", err
+	}
+
+	next, ok := nextLink(resp.Header.Values("Link"))
+	if !ok {
+		return "", nil
+	}
+
+	nextURL, err := req.URL.Parse(next)
+	if err != nil {
+		return "", fmt.Errorf("invalid next page link %q: %w", next, err)
+	}
+
+	return nextURL.String(), nil
+}
+
+// nextLink returns the target of the first link with a rel of "next" in
+// the given Link header values.
+func nextLink(values []string) (string, bool) {
+	for _, value := range values {
+		for value != "" {
+			start := strings.IndexByte(value, '<')
+			end := strings.IndexByte(value, '>')
+			if start < 0 || end < start {
+				break
+			}
+
+			target := value[start+1 : end]
+			params, rest, _ := strings.Cut(value[end+1:], ",")
+			value = rest
+
+			if hasRel(params, "next") {
+				return target, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// hasRel reports whether the link parameters include rel, which may hold
+// several space separated relation types.
+func hasRel(params, rel string) bool {
+	for _, param := range strings.Split(params, ";") {
+		name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if !ok || !strings.EqualFold(strings.TrimSpace(name), "rel") {
+			continue
+		}
+
+		for _, relType := range strings.Fields(strings.Trim(strings.TrimSpace(value), `"`)) {
+			if strings.EqualFold(relType, rel) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
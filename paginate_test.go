@@ -0,0 +1,103 @@
+package go_http_client_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	httpclient "github.com/JSainsburyPLC/danielchurm/go-http-client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GetAllPages", func() {
+	var server *httptest.Server
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/items":
+				w.Header().Add("Link", `</items/2>; rel="next", </items>; rel="first"`)
+				_, _ = w.Write([]byte("page 1"))
+			case "/items/2":
+				w.Header().Add("Link", `</items>; rel="prev"`)
+				w.Header().Add("Link", `<`+"http://"+r.Host+`/items/3>; rel="next last"`)
+				_, _ = w.Write([]byte("page 2"))
+			case "/items/3":
+				_, _ = w.Write([]byte("page 3"))
+			case "/loop":
+				w.Header().Set("Link", `</loop>; rel=next`)
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("follows next links until the last page", func() {
+		var pages []string
+		err := httpclient.GetAllPages(context.Background(), baseBuilder().Build(), server.URL+"/items", func(resp *http.Response) error {
+			body, err := io.ReadAll(resp.Body)
+			pages = append(pages, string(body))
+			return err
+		})
+
+		Expect(err).ToNot(HaveOccurred(), "pagination failed")
+		Expect(pages).To(Equal([]string{"page 1", "page 2", "page 3"}))
+	})
+
+	It("stops at the first error from each", func() {
+		errStop := errors.New("stop")
+		calls := 0
+		err := httpclient.GetAllPages(context.Background(), baseBuilder().Build(), server.URL+"/items", func(resp *http.Response) error {
+			calls++
+			return errStop
+		})
+
+		Expect(err).To(MatchError(errStop))
+		Expect(calls).To(Equal(1), "pagination continued after an error")
+	})
+
+	It("returns a status error for a failed page", func() {
+		err := httpclient.GetAllPages(context.Background(), baseBuilder().Build(), server.URL+"/missing", func(resp *http.Response) error {
+			Fail("each called for a failed page")
+			return nil
+		})
+
+		var statusErr *httpclient.StatusError
+		Expect(errors.As(err, &statusErr)).To(BeTrue(), "error is not a StatusError")
+		Expect(statusErr.StatusCode).To(Equal(http.StatusNotFound))
+	})
+
+	It("accepts pages with statuses configured as success", func() {
+		var statuses []int
+		err := httpclient.GetAllPages(context.Background(), baseBuilder().Build(), server.URL+"/missing", func(resp *http.Response) error {
+			statuses = append(statuses, resp.StatusCode)
+			return nil
+		}, httpclient.WithSuccessStatusCodes(http.StatusNotFound))
+
+		Expect(err).ToNot(HaveOccurred(), "configured success status rejected")
+		Expect(statuses).To(Equal([]int{http.StatusNotFound}))
+	})
+
+	It("refuses to follow a link back to a page already fetched", func() {
+		err := httpclient.GetAllPages(context.Background(), baseBuilder().Build(), server.URL+"/loop", func(resp *http.Response) error {
+			return nil
+		})
+		Expect(err).To(MatchError(ContainSubstring("loops back")))
+	})
+
+	It("stops once the context is cancelled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		err := httpclient.GetAllPages(ctx, baseBuilder().Build(), server.URL+"/items", func(resp *http.Response) error {
+			cancel()
+			return nil
+		})
+		Expect(errors.Is(err, context.Canceled)).To(BeTrue(), "pagination continued after cancellation")
+	})
+})
@@ -0,0 +1,67 @@
+package go_http_client
+
+import (
+	"net/http"
+	"strings"
+)
+
+// headerPassthroughTransport copies the allowlisted headers of an incoming
+// request, stored as an http.Header in the request context, onto outgoing
+// requests that do not set them.
+type headerPassthroughTransport struct {
+	wrapped  http.RoundTripper
+	names    map[string]bool
+	prefixes []string
+	ctxKey   any
+}
+
+func newHeaderPassthroughTransport(wrapped http.RoundTripper, names []string, ctxKey any) http.RoundTripper {
+	t := &headerPassthroughTransport{
+		wrapped: wrapped,
+		names:   map[string]bool{},
+		ctxKey:  ctxKey,
+	}
+
+	for _, name := range names {
+		if prefix, ok := strings.CutSuffix(name, "*"); ok {
+			t.prefixes = append(t.prefixes, http.CanonicalHeaderKey(prefix))
+		} else {
+			t.names[http.CanonicalHeaderKey(name)] = true
+		}
+	}
+
+	return t
+}
+
+func (t headerPassthroughTransport) allowed(key string) bool {
+	if t.names[key] {
+		return true
+	}
+
+	for _, prefix := range t.prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t headerPassthroughTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	incoming, _ := req.Context().Value(t.ctxKey).(http.Header)
+	if len(incoming) == 0 {
+		return t.wrapped.RoundTrip(req)
+	}
+
+	req = req.Clone(req.Context())
+	for key, values := range incoming {
+		key = http.CanonicalHeaderKey(key)
+		if _, ok := req.Header[key]; ok || !t.allowed(key) {
+			continue
+		}
+
+		req.Header[key] = append([]string(nil), values...)
+	}
+
+	return t.wrapped.RoundTrip(req)
+}
@@ -0,0 +1,80 @@
+package go_http_client_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type incomingHeadersKey struct{}
+
+var _ = Describe("Header Passthrough", func() {
+	var (
+		server   *httptest.Server
+		received http.Header
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = r.Header.Clone()
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	get := func(client *http.Client, ctx context.Context, header http.Header) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		Expect(err).ToNot(HaveOccurred())
+		for key, values := range header {
+			req.Header[key] = values
+		}
+
+		resp, err := client.Do(req)
+		Expect(err).ToNot(HaveOccurred(), "request failed")
+		Expect(resp.Body.Close()).To(Succeed())
+	}
+
+	It("propagates only allowlisted headers", func() {
+		client := baseBuilder().
+			WithHeaderPassthrough([]string{"traceparent", "Baggage", "x-b3-*"}, incomingHeadersKey{}).
+			Build()
+
+		incoming := http.Header{
+			"Traceparent":   {"00-trace-span-01"},
+			"Baggage":       {"tenant=acme"},
+			"X-B3-Traceid":  {"abc"},
+			"X-B3-Spanid":   {"def"},
+			"Authorization": {"Bearer secret"},
+			"Cookie":        {"session=1"},
+		}
+		get(client, context.WithValue(context.Background(), incomingHeadersKey{}, incoming), nil)
+
+		Expect(received.Get("Traceparent")).To(Equal("00-trace-span-01"))
+		Expect(received.Get("Baggage")).To(Equal("tenant=acme"))
+		Expect(received.Get("X-B3-Traceid")).To(Equal("abc"), "prefix match not propagated")
+		Expect(received.Get("X-B3-Spanid")).To(Equal("def"), "prefix match not propagated")
+		Expect(received.Get("Authorization")).To(BeEmpty(), "header outside the allowlist propagated")
+		Expect(received.Get("Cookie")).To(BeEmpty(), "header outside the allowlist propagated")
+	})
+
+	It("does not override headers set on the request", func() {
+		client := baseBuilder().WithHeaderPassthrough([]string{"traceparent"}, incomingHeadersKey{}).Build()
+
+		incoming := http.Header{"Traceparent": {"incoming"}}
+		get(client, context.WithValue(context.Background(), incomingHeadersKey{}, incoming), http.Header{"Traceparent": {"outgoing"}})
+
+		Expect(received.Values("Traceparent")).To(Equal([]string{"outgoing"}))
+	})
+
+	It("sends requests without incoming headers unchanged", func() {
+		client := baseBuilder().WithHeaderPassthrough([]string{"traceparent"}, incomingHeadersKey{}).Build()
+
+		get(client, context.Background(), nil)
+		Expect(received.Get("Traceparent")).To(BeEmpty())
+	})
+})
@@ -0,0 +1,60 @@
+package go_http_client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+var PingTimeout = 5 * time.Second
+
+// StatusError is returned when a response has a status code the caller did
+// not expect.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code %d", e.StatusCode)
+}
+
+// Ping checks that url responds with a 2XX status, sending a HEAD request
+// (or a GET if HEAD is not allowed) bounded by PingTimeout. The request goes
+// through the client's transport chain, so a ping to a host whose circuit
+// breaker is open fails fast, and a successful ping while the breaker is
+// half-open counts towards closing it.
+func Ping(ctx context.Context, client *http.Client, url string) error {
+	ctx, cancel := context.WithTimeout(ctx, PingTimeout)
+	defer cancel()
+
+	statusCode, err := ping(ctx, client, http.MethodHead, url)
+	if err == nil && statusCode == http.StatusMethodNotAllowed {
+		statusCode, err = ping(ctx, client, http.MethodGet, url)
+	}
+
+	if err != nil {
+		return fmt.Errorf("ping failed: %w", err)
+	}
+
+	if statusCode < 200 || statusCode > 299 {
+		return &StatusError{StatusCode: statusCode}
+	}
+
+	return nil
+}
+
+func ping(ctx context.Context, client *http.Client, method, url string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+
+	_ = resp.Body.Close()
+	return resp.StatusCode, nil
+}
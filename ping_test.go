@@ -0,0 +1,62 @@
+package go_http_client_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	httpclient "github.com/JSainsburyPLC/danielchurm/go-http-client"
+	"github.com/JSainsburyPLC/danielchurm/go-http-client/circuitbreaker"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sony/gobreaker/v2"
+)
+
+var _ = Describe("Ping", func() {
+	It("succeeds for a healthy target", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Method).To(Equal(http.MethodHead))
+		}))
+		defer server.Close()
+
+		Expect(httpclient.Ping(context.Background(), baseBuilder().Build(), server.URL)).To(Succeed())
+	})
+
+	It("falls back to GET when HEAD is not allowed", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		}))
+		defer server.Close()
+
+		Expect(httpclient.Ping(context.Background(), baseBuilder().Build(), server.URL)).To(Succeed())
+	})
+
+	It("returns a status error for an unhealthy target", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		err := httpclient.Ping(context.Background(), baseBuilder().Build(), server.URL)
+
+		var statusErr *httpclient.StatusError
+		Expect(err).To(BeAssignableToTypeOf(statusErr))
+		Expect(err.(*httpclient.StatusError).StatusCode).To(Equal(http.StatusServiceUnavailable))
+	})
+
+	It("fails fast while the circuit breaker is open", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		client := baseBuilder().WithCircuitBreakerSettings(circuitbreaker.Settings{
+			Settings: gobreaker.Settings{ReadyToTrip: func(gobreaker.Counts) bool { return true }},
+		}).Build()
+
+		Expect(httpclient.Ping(context.Background(), client, server.URL)).ToNot(Succeed())
+		Expect(httpclient.Ping(context.Background(), client, server.URL)).To(MatchError(gobreaker.ErrOpenState))
+	})
+})
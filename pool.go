@@ -0,0 +1,264 @@
+package go_http_client
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"reflect"
+	"time"
+
+	"golang.org/x/net/proxy"
+)
+
+// PoolSettings configures the base transport that the rest of the client
+// wraps. Zero values keep the defaults of http.DefaultTransport.
+type PoolSettings struct {
+	MaxIdleConns          int
+	MaxIdleConnsPerHost   int
+	MaxConnsPerHost       int
+	IdleConnTimeout       time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration
+
+	// ConnectTimeout, when set, bounds establishing a connection, covering
+	// the DNS lookup and dial, independent of the client Timeout. Unless
+	// TLSHandshakeTimeout is also set, it bounds the TLS handshake too.
+	ConnectTimeout time.Duration
+
+	ExpectContinueTimeout time.Duration
+	DisableKeepAlives     bool
+	DisableCompression    bool
+
+	// CompressionEncodings, when DisableCompression is set, are the
+	// encodings to request with Accept-Encoding and transparently
+	// decompress. gzip and deflate are supported. Requests that set their
+	// own Accept-Encoding are left for the caller to decompress.
+	CompressionEncodings []string
+
+	// ResponseBodyTimeout, when set, fails reads of a response body with
+	// ErrResponseBodyTimeout once a read has waited that long without any
+	// bytes arriving. Time spent between reads is not counted. Unlike
+	// ResponseHeaderTimeout it protects against servers that stall part way
+	// through the body.
+	ResponseBodyTimeout time.Duration
+
+	// ReadIdleTimeout, when set, makes the client ping an HTTP/2 connection
+	// on which no frames have been received for that long, closing it if no
+	// response arrives within PingTimeout (15 seconds if unset). This detects
+	// connections that went dead silently before a request stalls on them.
+	ReadIdleTimeout time.Duration
+	PingTimeout     time.Duration
+
+	// ReadBufferSize and WriteBufferSize set the size of the buffers used
+	// for each connection. Zero keeps the stdlib default of 4KB.
+	ReadBufferSize  int
+	WriteBufferSize int
+
+	// DualStack enables tuning of the "Happy Eyeballs" fallback between
+	// IPv6 and IPv4. When set, FallbackDelay is how long the dialer waits
+	// for the primary address family before racing the other one; a
+	// negative value disables the fallback entirely.
+	DualStack     bool
+	FallbackDelay time.Duration
+
+	// LocalAddr is the local address outbound connections are made from,
+	// for hosts with several interfaces. When nil one is chosen by the OS.
+	LocalAddr net.Addr
+
+	// UnixSocket, when set, is the path of a unix domain socket every
+	// connection is made to, regardless of the host in the request URL.
+	UnixSocket string
+
+	// SOCKS5ProxyAddr, when set, routes every connection through the SOCKS5
+	// proxy at that address, authenticating with SOCKS5ProxyAuth if given.
+	// HTTPS targets are still negotiated end to end over the proxied
+	// connection. UnixSocket takes precedence over the proxy.
+	SOCKS5ProxyAddr string
+	SOCKS5ProxyAuth *proxy.Auth
+
+	// TLSClientConfig, when set, replaces the TLS configuration of the
+	// transport, for example to trust a private CA. TLSServerName, when set,
+	// is the name presented with SNI and verified against the server's
+	// certificate instead of the host in the request URL.
+	TLSClientConfig *tls.Config
+	TLSServerName   string
+
+	// MinTLSVersion, when set, is the lowest TLS version the transport
+	// negotiates, such as tls.VersionTLS13.
+	MinTLSVersion uint16
+}
+
+// merge returns the settings with every non-zero field of override applied
+// on top. Zero values in override are treated as unset, so an override can
+// switch a flag on but not back off.
+func (ps PoolSettings) merge(override PoolSettings) PoolSettings {
+	mergeValue(&ps.MaxIdleConns, override.MaxIdleConns)
+	mergeValue(&ps.MaxIdleConnsPerHost, override.MaxIdleConnsPerHost)
+	mergeValue(&ps.MaxConnsPerHost, override.MaxConnsPerHost)
+	mergeValue(&ps.IdleConnTimeout, override.IdleConnTimeout)
+	mergeValue(&ps.TLSHandshakeTimeout, override.TLSHandshakeTimeout)
+	mergeValue(&ps.ResponseHeaderTimeout, override.ResponseHeaderTimeout)
+	mergeValue(&ps.ConnectTimeout, override.ConnectTimeout)
+	mergeValue(&ps.ExpectContinueTimeout, override.ExpectContinueTimeout)
+	mergeValue(&ps.DisableKeepAlives, override.DisableKeepAlives)
+	mergeValue(&ps.DisableCompression, override.DisableCompression)
+	mergeValue(&ps.ResponseBodyTimeout, override.ResponseBodyTimeout)
+	mergeValue(&ps.ReadIdleTimeout, override.ReadIdleTimeout)
+	mergeValue(&ps.PingTimeout, override.PingTimeout)
+	mergeValue(&ps.ReadBufferSize, override.ReadBufferSize)
+	mergeValue(&ps.WriteBufferSize, override.WriteBufferSize)
+	mergeValue(&ps.DualStack, override.DualStack)
+	mergeValue(&ps.FallbackDelay, override.FallbackDelay)
+	mergeValue(&ps.UnixSocket, override.UnixSocket)
+	mergeValue(&ps.SOCKS5ProxyAddr, override.SOCKS5ProxyAddr)
+	mergeValue(&ps.TLSServerName, override.TLSServerName)
+	mergeValue(&ps.MinTLSVersion, override.MinTLSVersion)
+
+	if override.CompressionEncodings != nil {
+		ps.CompressionEncodings = override.CompressionEncodings
+	}
+
+	if override.LocalAddr != nil {
+		ps.LocalAddr = override.LocalAddr
+	}
+
+	if override.SOCKS5ProxyAuth != nil {
+		ps.SOCKS5ProxyAuth = override.SOCKS5ProxyAuth
+	}
+
+	if override.TLSClientConfig != nil {
+		ps.TLSClientConfig = override.TLSClientConfig
+	}
+
+	return ps
+}
+
+func (ps PoolSettings) isZero() bool {
+	return reflect.ValueOf(ps).IsZero()
+}
+
+func mergeValue[T comparable](dst *T, override T) {
+	var zero T
+	if override != zero {
+		*dst = override
+	}
+}
+
+func newDialer(settings PoolSettings) *net.Dialer {
+	// Mirrors the dialer used by http.DefaultTransport.
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		LocalAddr: settings.LocalAddr,
+	}
+
+	if settings.ConnectTimeout > 0 {
+		dialer.Timeout = settings.ConnectTimeout
+	}
+
+	if settings.DualStack {
+		dialer.FallbackDelay = settings.FallbackDelay
+	}
+
+	return dialer
+}
+
+func newBaseTransport(settings PoolSettings) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	dialer := newDialer(settings)
+	transport.DialContext = dialer.DialContext
+
+	if settings.UnixSocket != "" {
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", settings.UnixSocket)
+		}
+	} else if settings.SOCKS5ProxyAddr != "" {
+		transport.Proxy = nil
+		transport.DialContext = newSOCKS5DialContext(settings, dialer)
+	}
+
+	if settings.MaxIdleConns > 0 {
+		transport.MaxIdleConns = settings.MaxIdleConns
+	}
+
+	if settings.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = settings.MaxIdleConnsPerHost
+	}
+
+	if settings.MaxConnsPerHost > 0 {
+		transport.MaxConnsPerHost = settings.MaxConnsPerHost
+	}
+
+	if settings.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = settings.IdleConnTimeout
+	}
+
+	if settings.TLSHandshakeTimeout > 0 {
+		transport.TLSHandshakeTimeout = settings.TLSHandshakeTimeout
+	} else if settings.ConnectTimeout > 0 {
+		transport.TLSHandshakeTimeout = settings.ConnectTimeout
+	}
+
+	if settings.ResponseHeaderTimeout > 0 {
+		transport.ResponseHeaderTimeout = settings.ResponseHeaderTimeout
+	}
+
+	if settings.ExpectContinueTimeout > 0 {
+		transport.ExpectContinueTimeout = settings.ExpectContinueTimeout
+	}
+
+	if settings.ReadBufferSize > 0 {
+		transport.ReadBufferSize = settings.ReadBufferSize
+	}
+
+	if settings.WriteBufferSize > 0 {
+		transport.WriteBufferSize = settings.WriteBufferSize
+	}
+
+	if settings.ReadIdleTimeout > 0 {
+		http2 := &http.HTTP2Config{}
+		if transport.HTTP2 != nil {
+			*http2 = *transport.HTTP2
+		}
+
+		http2.SendPingTimeout = settings.ReadIdleTimeout
+		http2.PingTimeout = settings.PingTimeout
+		transport.HTTP2 = http2
+	}
+
+	if settings.TLSClientConfig != nil {
+		transport.TLSClientConfig = settings.TLSClientConfig.Clone()
+	}
+
+	if settings.TLSServerName != "" {
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+		transport.TLSClientConfig.ServerName = settings.TLSServerName
+	}
+
+	if settings.MinTLSVersion != 0 {
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+		transport.TLSClientConfig.MinVersion = settings.MinTLSVersion
+	}
+
+	transport.DisableKeepAlives = settings.DisableKeepAlives
+	transport.DisableCompression = settings.DisableCompression
+
+	return transport
+}
+
+func newSOCKS5DialContext(settings PoolSettings, forward *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	dialer, err := proxy.SOCKS5("tcp", settings.SOCKS5ProxyAddr, settings.SOCKS5ProxyAuth, forward)
+	if err != nil {
+		return func(context.Context, string, string) (net.Conn, error) {
+			return nil, fmt.Errorf("failed to create SOCKS5 dialer: %w", err)
+		}
+	}
+
+	return dialer.(proxy.ContextDialer).DialContext
+}
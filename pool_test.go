@@ -0,0 +1,317 @@
+package go_http_client_test
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	httpclient "github.com/JSainsburyPLC/danielchurm/go-http-client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Pool Settings", func() {
+	It("keeps the stdlib fallback delay by default", func() {
+		dialer := httpclient.NewDialer(httpclient.PoolSettings{FallbackDelay: time.Second})
+		Expect(dialer.FallbackDelay).To(BeZero(), "fallback delay set without DualStack")
+	})
+
+	It("configures the dialer with the given fallback delay", func() {
+		dialer := httpclient.NewDialer(httpclient.PoolSettings{DualStack: true, FallbackDelay: 50 * time.Millisecond})
+		Expect(dialer.FallbackDelay).To(Equal(50*time.Millisecond), "fallback delay not applied")
+	})
+
+	It("disables the fallback with a negative delay", func() {
+		dialer := httpclient.NewDialer(httpclient.PoolSettings{DualStack: true, FallbackDelay: -1})
+		Expect(dialer.FallbackDelay).To(BeNumerically("<", 0), "fallback not disabled")
+	})
+
+	It("dials from the requested local address", func() {
+		localAddr := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)}
+
+		dialer := httpclient.NewDialer(httpclient.PoolSettings{LocalAddr: localAddr})
+		Expect(dialer.LocalAddr).To(Equal(localAddr), "local address not applied")
+
+		var remoteAddr string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			remoteAddr = r.RemoteAddr
+		}))
+		defer server.Close()
+
+		client := baseBuilder().WithPoolSettings(httpclient.PoolSettings{LocalAddr: localAddr}).Build()
+		resp, err := client.Get(server.URL)
+		Expect(err).ToNot(HaveOccurred(), "request failed")
+		Expect(resp.Body.Close()).To(Succeed())
+
+		host, _, err := net.SplitHostPort(remoteAddr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(host).To(Equal("127.0.0.1"), "request did not originate from the local address")
+	})
+
+	It("leaves the local address unset by default", func() {
+		dialer := httpclient.NewDialer(httpclient.PoolSettings{})
+		Expect(dialer.LocalAddr).To(BeNil(), "local address set")
+	})
+
+	It("round-trips requests over a unix socket", func() {
+		listener, err := net.Listen("unix", filepath.Join(GinkgoT().TempDir(), "client.sock"))
+		Expect(err).ToNot(HaveOccurred(), "failed to listen on unix socket")
+
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(r.URL.Path))
+		}))
+		server.Listener = listener
+		server.Start()
+		defer server.Close()
+
+		client := baseBuilder().WithUnixSocket(listener.Addr().String()).Build()
+		resp, err := client.Get("http://unix/ping")
+		Expect(err).ToNot(HaveOccurred(), "request failed")
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(body)).To(Equal("/ping"), "request not served over the socket")
+	})
+
+	It("proxies traffic through a SOCKS5 proxy", func() {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("proxied"))
+		}))
+		defer server.Close()
+
+		socks := newSOCKS5Server()
+		defer socks.Close()
+
+		transport := httpclient.BaseTransport(baseBuilder().WithSOCKS5Proxy(socks.Addr(), nil))
+		transport.TLSClientConfig = server.Client().Transport.(*http.Transport).TLSClientConfig
+		client := &http.Client{Transport: transport}
+
+		resp, err := client.Get(server.URL)
+		Expect(err).ToNot(HaveOccurred(), "request failed")
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(body)).To(Equal("proxied"), "unexpected response body")
+		Expect(socks.Targets()).To(ConsistOf(server.Listener.Addr().String()), "request was not proxied")
+	})
+
+	It("shares the default transport when no pool settings are given", func() {
+		client := baseBuilder().Build()
+		Expect(httpclient.BuiltTransport(client, "example.com")).To(BeIdenticalTo(http.DefaultTransport), "client built a pool of its own")
+	})
+
+	It("builds a dedicated transport once a pool setting is given", func() {
+		client := baseBuilder().WithPoolSettings(httpclient.PoolSettings{MaxIdleConnsPerHost: 7}).Build()
+		Expect(httpclient.BuiltTransport(client, "example.com")).ToNot(BeIdenticalTo(http.DefaultTransport), "pool settings applied to the shared transport")
+	})
+
+	It("merges repeated pool settings", func() {
+		transport := httpclient.BaseTransport(baseBuilder().
+			WithPoolSettings(httpclient.PoolSettings{
+				MaxIdleConns:        50,
+				MaxIdleConnsPerHost: 10,
+				IdleConnTimeout:     time.Minute,
+				DisableCompression:  true,
+			}).
+			WithPoolSettings(httpclient.PoolSettings{MaxIdleConns: 200}))
+
+		Expect(transport.MaxIdleConns).To(Equal(200), "override not applied")
+		Expect(transport.MaxIdleConnsPerHost).To(Equal(10), "base settings lost")
+		Expect(transport.IdleConnTimeout).To(Equal(time.Minute), "base settings lost")
+		Expect(transport.DisableCompression).To(BeTrue(), "base settings lost")
+	})
+
+	It("lets a later call switch flags back off", func() {
+		cb := baseBuilder().
+			WithPoolSettings(httpclient.PoolSettings{
+				DisableKeepAlives:  true,
+				DisableCompression: true,
+				DualStack:          true,
+				FallbackDelay:      time.Second,
+			}).
+			WithKeepAlives(true).
+			WithCompression(true).
+			WithDualStack(false)
+
+		transport := httpclient.BaseTransport(cb)
+		Expect(transport.DisableKeepAlives).To(BeFalse(), "keep-alives not re-enabled")
+		Expect(transport.DisableCompression).To(BeFalse(), "compression not re-enabled")
+		Expect(httpclient.NewDialer(cb.Pool).FallbackDelay).To(BeZero(), "dual stack tuning not switched off")
+	})
+
+	It("keeps flags set by an earlier call when merging", func() {
+		cb := baseBuilder().
+			WithPoolSettings(httpclient.PoolSettings{DisableKeepAlives: true}).
+			WithPoolSettings(httpclient.PoolSettings{DisableKeepAlives: false, MaxIdleConns: 5})
+
+		Expect(httpclient.BaseTransport(cb).DisableKeepAlives).To(BeTrue(), "unset flag cleared an earlier one")
+	})
+
+	It("uses the pool settings for the base transport", func() {
+		client := baseBuilder().WithPoolSettings(httpclient.PoolSettings{MaxIdleConnsPerHost: 7}).Build()
+		transport := httpclient.BuiltTransport(client, "example.com")
+
+		Expect(transport.MaxIdleConnsPerHost).To(Equal(7), "pool settings not applied")
+		Expect(transport.DialContext).ToNot(BeNil(), "dialer not configured")
+	})
+
+	It("propagates buffer sizes to the base transport", func() {
+		client := baseBuilder().
+			WithPoolSettings(httpclient.PoolSettings{ReadBufferSize: 64 << 10, WriteBufferSize: 32 << 10}).
+			Build()
+		transport := httpclient.BuiltTransport(client, "example.com")
+
+		Expect(transport.ReadBufferSize).To(Equal(64<<10), "read buffer size not applied")
+		Expect(transport.WriteBufferSize).To(Equal(32<<10), "write buffer size not applied")
+	})
+
+	It("configures HTTP/2 health check pings", func() {
+		transport := httpclient.BaseTransport(baseBuilder().WithPoolSettings(httpclient.PoolSettings{
+			ReadIdleTimeout: 30 * time.Second,
+			PingTimeout:     5 * time.Second,
+		}))
+
+		Expect(transport.HTTP2).ToNot(BeNil(), "HTTP/2 not configured")
+		Expect(transport.HTTP2.SendPingTimeout).To(Equal(30*time.Second), "read idle timeout not applied")
+		Expect(transport.HTTP2.PingTimeout).To(Equal(5*time.Second), "ping timeout not applied")
+		Expect(transport.ForceAttemptHTTP2).To(BeTrue(), "HTTP/2 no longer attempted")
+	})
+
+	It("leaves HTTP/2 pings disabled by default", func() {
+		transport := httpclient.BaseTransport(baseBuilder())
+		if transport.HTTP2 != nil {
+			Expect(transport.HTTP2.SendPingTimeout).To(BeZero(), "pings enabled by default")
+		}
+	})
+
+	It("keeps the default buffer sizes when unset", func() {
+		transport := httpclient.BaseTransport(baseBuilder())
+		Expect(transport.ReadBufferSize).To(BeZero(), "read buffer size changed")
+		Expect(transport.WriteBufferSize).To(BeZero(), "write buffer size changed")
+	})
+
+	It("bounds the dial with the connect timeout", func() {
+		dialer := httpclient.NewDialer(httpclient.PoolSettings{ConnectTimeout: time.Second})
+		Expect(dialer.Timeout).To(Equal(time.Second), "connect timeout not applied to the dialer")
+	})
+
+	It("fails a stalled connection within the connect timeout", func() {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred(), "failed to listen")
+		defer listener.Close()
+
+		// Accept connections but never answer the TLS handshake.
+		go func() {
+			var conns []net.Conn
+			defer func() {
+				for _, conn := range conns {
+					_ = conn.Close()
+				}
+			}()
+
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				conns = append(conns, conn)
+			}
+		}()
+
+		client := baseBuilder().
+			WithTimeout(time.Minute).
+			WithPoolSettings(httpclient.PoolSettings{ConnectTimeout: 100 * time.Millisecond}).
+			Build()
+
+		start := time.Now()
+		_, err = client.Get("https://" + listener.Addr().String())
+		Expect(err).To(HaveOccurred(), "stalled connection succeeded")
+		Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second), "connect timeout not applied")
+	})
+})
+
+// socks5Server is a minimal unauthenticated SOCKS5 proxy supporting CONNECT
+// to IPv4 addresses, recording the targets it was asked to connect to.
+type socks5Server struct {
+	listener net.Listener
+	mu       sync.Mutex
+	targets  []string
+}
+
+func newSOCKS5Server() *socks5Server {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).ToNot(HaveOccurred(), "failed to start SOCKS5 server")
+
+	s := &socks5Server{listener: listener}
+	go s.serve()
+	return s
+}
+
+func (s *socks5Server) Addr() string { return s.listener.Addr().String() }
+
+func (s *socks5Server) Close() { _ = s.listener.Close() }
+
+func (s *socks5Server) Targets() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.targets...)
+}
+
+func (s *socks5Server) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *socks5Server) handle(conn net.Conn) {
+	defer conn.Close()
+
+	// greeting: version, method count, methods
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return
+	}
+	if _, err := io.ReadFull(conn, make([]byte, header[1])); err != nil {
+		return
+	}
+	if _, err := conn.Write([]byte{5, 0}); err != nil {
+		return
+	}
+
+	// request: version, CONNECT, reserved, IPv4 address type, address, port
+	request := make([]byte, 10)
+	if _, err := io.ReadFull(conn, request); err != nil || request[3] != 1 {
+		return
+	}
+	target := net.JoinHostPort(net.IP(request[4:8]).String(), strconv.Itoa(int(binary.BigEndian.Uint16(request[8:]))))
+
+	upstream, err := net.Dial("tcp", target)
+	if err != nil {
+		_, _ = conn.Write([]byte{5, 1, 0, 1, 0, 0, 0, 0, 0, 0})
+		return
+	}
+	defer upstream.Close()
+
+	s.mu.Lock()
+	s.targets = append(s.targets, target)
+	s.mu.Unlock()
+
+	if _, err := conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0}); err != nil {
+		return
+	}
+
+	go func() { _, _ = io.Copy(upstream, conn) }()
+	_, _ = io.Copy(conn, upstream)
+}
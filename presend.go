@@ -0,0 +1,23 @@
+package go_http_client
+
+import "net/http"
+
+type preSendTransport struct {
+	wrapped http.RoundTripper
+	hook    func(*http.Request)
+}
+
+func newPreSendTransport(wrapped http.RoundTripper, hook func(*http.Request)) http.RoundTripper {
+	return &preSendTransport{
+		wrapped: wrapped,
+		hook:    hook,
+	}
+}
+
+// RoundTrip passes the hook a copy of the request, so changes to it have no
+// effect on what is sent. The copy shares the request body, which the hook
+// must not read.
+func (t preSendTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.hook(req.Clone(req.Context()))
+	return t.wrapped.RoundTrip(req)
+}
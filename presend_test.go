@@ -0,0 +1,66 @@
+package go_http_client_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	httpclient "github.com/JSainsburyPLC/danielchurm/go-http-client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Pre-send Hook", func() {
+	var (
+		server   *httptest.Server
+		received http.Header
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = r.Header.Clone()
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("observes the fully decorated request", func() {
+		var seen []*http.Request
+		client := baseBuilder().
+			WithStaticHeader("X-Api-Version", "2").
+			WithPreSendHook(func(req *http.Request) { seen = append(seen, req) }).
+			Build()
+
+		ctx := httpclient.WithRequestHeaders(context.Background(), http.Header{"X-Tenant": {"acme"}})
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		resp, err := client.Do(req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Body.Close()).To(Succeed())
+
+		Expect(seen).To(HaveLen(1))
+		Expect(seen[0].Header.Get("X-Api-Version")).To(Equal("2"), "static header not stamped before the hook")
+		Expect(seen[0].Header.Get("X-Tenant")).To(Equal("acme"), "context header not stamped before the hook")
+		Expect(seen[0].URL.String()).To(Equal(server.URL))
+	})
+
+	It("cannot change the request that is sent", func() {
+		client := baseBuilder().
+			WithPreSendHook(func(req *http.Request) { req.Header.Set("X-Injected", "true") }).
+			Build()
+
+		resp, err := client.Get(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Body.Close()).To(Succeed())
+
+		Expect(received.Get("X-Injected")).To(BeEmpty(), "hook mutated the outgoing request")
+	})
+
+	It("sits just above the base transport", func() {
+		chain := httpclient.Default.WithPreSendHook(func(*http.Request) {}).TransportChain()
+		Expect(chain[len(chain)-2:]).To(Equal([]string{"pre-send", "base"}))
+	})
+})
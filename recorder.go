@@ -0,0 +1,99 @@
+package go_http_client
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// RecordedRequest is a snapshot of a request as the client sent it.
+type RecordedRequest struct {
+	Method string
+	URL    string
+	Header http.Header
+	Body   []byte
+}
+
+// Recorder captures every request a client sends, after all other layers
+// have decorated it, for example to compare against golden files in tests.
+// The zero value is ready to use.
+type Recorder struct {
+	mu       sync.Mutex
+	requests []RecordedRequest
+}
+
+// Requests returns the requests recorded so far, oldest first.
+func (r *Recorder) Requests() []RecordedRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]RecordedRequest(nil), r.requests...)
+}
+
+// Reset discards the requests recorded so far.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = nil
+}
+
+func (r *Recorder) record(request RecordedRequest) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, request)
+}
+
+type recorderTransport struct {
+	wrapped  http.RoundTripper
+	recorder *Recorder
+}
+
+func newRecorderTransport(wrapped http.RoundTripper, recorder *Recorder) http.RoundTripper {
+	return &recorderTransport{
+		wrapped:  wrapped,
+		recorder: recorder,
+	}
+}
+
+// RoundTrip buffers the request body so it can be both recorded and sent.
+func (t recorderTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req, body, err := bufferRequestBody(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record request: %w", err)
+	}
+
+	t.recorder.record(snapshotRequest(req, body))
+	return t.wrapped.RoundTrip(req)
+}
+
+// bufferRequestBody reads the body of req into memory, returning it along
+// with a copy of req whose body and GetBody replay the buffered bytes.
+func bufferRequestBody(req *http.Request) (*http.Request, []byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, nil, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	req = req.Clone(req.Context())
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+
+	return req, body, nil
+}
+
+func snapshotRequest(req *http.Request, body []byte) RecordedRequest {
+	return RecordedRequest{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Header: req.Header.Clone(),
+		Body:   body,
+	}
+}
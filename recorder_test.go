@@ -0,0 +1,80 @@
+package go_http_client_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	httpclient "github.com/JSainsburyPLC/danielchurm/go-http-client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Recorder", func() {
+	var (
+		server   *httptest.Server
+		received []byte
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received, _ = io.ReadAll(r.Body)
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("captures the fully decorated request", func() {
+		recorder := &httpclient.Recorder{}
+		client := baseBuilder().
+			WithStaticHeader("X-Api-Version", "2").
+			WithRecorder(recorder).
+			Build()
+
+		ctx := httpclient.WithRequestHeaders(context.Background(), http.Header{"X-Tenant": {"acme"}})
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL+"/orders?id=1", strings.NewReader(`{"sku":"123"}`))
+		Expect(err).ToNot(HaveOccurred())
+
+		resp, err := client.Do(req)
+		Expect(err).ToNot(HaveOccurred(), "request failed")
+		Expect(resp.Body.Close()).To(Succeed())
+
+		requests := recorder.Requests()
+		Expect(requests).To(HaveLen(1))
+		Expect(requests[0].Method).To(Equal(http.MethodPost))
+		Expect(requests[0].URL).To(Equal(server.URL + "/orders?id=1"))
+		Expect(requests[0].Header.Get("X-Api-Version")).To(Equal("2"), "static header not recorded")
+		Expect(requests[0].Header.Get("X-Tenant")).To(Equal("acme"), "context header not recorded")
+		Expect(string(requests[0].Body)).To(Equal(`{"sku":"123"}`), "body not recorded")
+	})
+
+	It("still sends the recorded body", func() {
+		recorder := &httpclient.Recorder{}
+		client := baseBuilder().WithRecorder(recorder).Build()
+
+		resp, err := client.Post(server.URL, "text/plain", strings.NewReader("payload"))
+		Expect(err).ToNot(HaveOccurred(), "request failed")
+		Expect(resp.Body.Close()).To(Succeed())
+
+		Expect(string(received)).To(Equal("payload"), "body not replayed to the server")
+	})
+
+	It("records requests without a body", func() {
+		recorder := &httpclient.Recorder{}
+		client := baseBuilder().WithRecorder(recorder).Build()
+
+		resp, err := client.Get(server.URL)
+		Expect(err).ToNot(HaveOccurred(), "request failed")
+		Expect(resp.Body.Close()).To(Succeed())
+
+		Expect(recorder.Requests()).To(HaveLen(1))
+		Expect(recorder.Requests()[0].Body).To(BeEmpty())
+
+		recorder.Reset()
+		Expect(recorder.Requests()).To(BeEmpty(), "reset kept recorded requests")
+	})
+})
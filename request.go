@@ -0,0 +1,49 @@
+package go_http_client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// NewRequestWithQuery builds a request for base with query encoded and
+// appended to any query base already has. Values for a key present in both
+// are kept, with those from base first.
+func NewRequestWithQuery(ctx context.Context, method, base string, query url.Values, body io.Reader) (*http.Request, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse request URL: %w", err)
+	}
+
+	merged := u.Query()
+	for key, values := range query {
+		for _, value := range values {
+			merged.Add(key, value)
+		}
+	}
+	u.RawQuery = merged.Encode()
+
+	return http.NewRequestWithContext(ctx, method, u.String(), body)
+}
+
+// NewStreamingRequest builds a request whose body is produced by
+// bodyFactory. The factory is called for the first send and again through
+// GetBody whenever the request has to be replayed, such as on a 307 or 308
+// redirect, so large generated bodies never need buffering.
+func NewStreamingRequest(ctx context.Context, method, url string, bodyFactory func() (io.ReadCloser, error)) (*http.Request, error) {
+	body, err := bodyFactory()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		_ = body.Close()
+		return nil, err
+	}
+	req.GetBody = bodyFactory
+
+	return req, nil
+}
@@ -0,0 +1,89 @@
+package go_http_client_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	httpclient "github.com/JSainsburyPLC/danielchurm/go-http-client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("New Request With Query", func() {
+	It("encodes special characters", func() {
+		req, err := httpclient.NewRequestWithQuery(context.Background(), http.MethodGet, "https://example.com/search",
+			url.Values{"q": {"fish & chips"}, "filter": {"a=b/c?"}}, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(req.URL.RawQuery).To(Equal("filter=a%3Db%2Fc%3F&q=fish+%26+chips"))
+		Expect(req.URL.Query().Get("q")).To(Equal("fish & chips"))
+	})
+
+	It("keeps repeated parameters", func() {
+		req, err := httpclient.NewRequestWithQuery(context.Background(), http.MethodGet, "https://example.com/items",
+			url.Values{"id": {"1", "2", "3"}}, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(req.URL.Query()["id"]).To(Equal([]string{"1", "2", "3"}))
+	})
+
+	It("merges with an existing query string", func() {
+		req, err := httpclient.NewRequestWithQuery(context.Background(), http.MethodGet, "https://example.com/items?page=2&id=1",
+			url.Values{"id": {"2"}, "sort": {"name"}}, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(req.URL.Path).To(Equal("/items"))
+		Expect(req.URL.Query()).To(Equal(url.Values{
+			"page": {"2"},
+			"id":   {"1", "2"},
+			"sort": {"name"},
+		}))
+	})
+
+	It("rejects invalid URLs", func() {
+		_, err := httpclient.NewRequestWithQuery(context.Background(), http.MethodGet, "://bad", nil, nil)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("New Streaming Request", func() {
+	It("creates a fresh body for each attempt", func() {
+		var received []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			received = append(received, string(body))
+
+			if r.URL.Path == "/old" {
+				http.Redirect(w, r, "/new", http.StatusTemporaryRedirect)
+			}
+		}))
+		defer server.Close()
+
+		calls := 0
+		req, err := httpclient.NewStreamingRequest(context.Background(), http.MethodPut, server.URL+"/old", func() (io.ReadCloser, error) {
+			calls++
+			return io.NopCloser(strings.NewReader("payload")), nil
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		resp, err := baseBuilder().Build().Do(req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Body.Close()).To(Succeed())
+
+		Expect(calls).To(Equal(2), "factory not invoked once per attempt")
+		Expect(received).To(Equal([]string{"payload", "payload"}), "body not sent on each attempt")
+	})
+
+	It("returns factory errors", func() {
+		factoryErr := errors.New("oh no")
+		_, err := httpclient.NewStreamingRequest(context.Background(), http.MethodPut, "http://example.com", func() (io.ReadCloser, error) {
+			return nil, factoryErr
+		})
+		Expect(err).To(MatchError(factoryErr))
+	})
+})
@@ -0,0 +1,53 @@
+package go_http_client
+
+import (
+	"errors"
+	"net/http"
+)
+
+var ErrNoMiddlewareResponse = errors.New("response middleware returned neither a response nor an error")
+
+// ResponseMiddleware processes a response before it is returned to the
+// caller, returning it or a replacement, or an error that fails the
+// request instead.
+type ResponseMiddleware func(*http.Response) (*http.Response, error)
+
+type responseMiddlewareTransport struct {
+	wrapped    http.RoundTripper
+	middleware []ResponseMiddleware
+}
+
+func newResponseMiddlewareTransport(wrapped http.RoundTripper, middleware []ResponseMiddleware) http.RoundTripper {
+	return &responseMiddlewareTransport{
+		wrapped:    wrapped,
+		middleware: middleware,
+	}
+}
+
+// RoundTrip runs the middleware in order, each receiving the response
+// returned by the one before. The first error stops the chain, closing the
+// body of the last response, and a middleware returning neither a response
+// nor an error fails the request with ErrNoMiddlewareResponse.
+func (t responseMiddlewareTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.wrapped.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, middleware := range t.middleware {
+		next, err := middleware(resp)
+		if err != nil {
+			_ = resp.Body.Close()
+			return nil, err
+		}
+
+		if next == nil {
+			_ = resp.Body.Close()
+			return nil, ErrNoMiddlewareResponse
+		}
+
+		resp = next
+	}
+
+	return resp, nil
+}
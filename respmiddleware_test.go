@@ -0,0 +1,93 @@
+package go_http_client_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	httpclient "github.com/JSainsburyPLC/danielchurm/go-http-client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Response Middleware", func() {
+	var server *httptest.Server
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/fail" {
+				w.WriteHeader(http.StatusConflict)
+			}
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	tag := func(calls *[]string, name string) httpclient.ResponseMiddleware {
+		return func(resp *http.Response) (*http.Response, error) {
+			*calls = append(*calls, name)
+			resp.Header.Add("X-Processed-By", name)
+			return resp, nil
+		}
+	}
+
+	It("runs middleware in the order added", func() {
+		var calls []string
+		client := baseBuilder().
+			WithResponseMiddleware(tag(&calls, "first"), tag(&calls, "second")).
+			WithResponseMiddleware(tag(&calls, "third")).
+			Build()
+
+		resp, err := client.Get(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Body.Close()).To(Succeed())
+
+		Expect(calls).To(Equal([]string{"first", "second", "third"}))
+		Expect(resp.Header.Values("X-Processed-By")).To(Equal([]string{"first", "second", "third"}))
+	})
+
+	It("stops the chain at the first error", func() {
+		errConflict := errors.New("conflict")
+		var calls []string
+		client := baseBuilder().
+			WithResponseMiddleware(
+				func(resp *http.Response) (*http.Response, error) {
+					calls = append(calls, "map-errors")
+					if resp.StatusCode == http.StatusConflict {
+						return nil, errConflict
+					}
+					return resp, nil
+				},
+				tag(&calls, "after"),
+			).
+			Build()
+
+		_, err := client.Get(server.URL + "/fail")
+		Expect(errors.Is(err, errConflict)).To(BeTrue(), "middleware error not returned")
+		Expect(calls).To(Equal([]string{"map-errors"}), "chain continued after an error")
+	})
+
+	It("fails the request when a middleware returns no response", func() {
+		var calls []string
+		client := baseBuilder().
+			WithResponseMiddleware(func(*http.Response) (*http.Response, error) { return nil, nil }, tag(&calls, "after")).
+			Build()
+
+		_, err := client.Get(server.URL)
+		Expect(errors.Is(err, httpclient.ErrNoMiddlewareResponse)).To(BeTrue(), "nil response not rejected")
+		Expect(calls).To(BeEmpty(), "chain continued with a nil response")
+	})
+
+	It("does not share middleware between builders", func() {
+		var calls []string
+		base := baseBuilder().WithResponseMiddleware(tag(&calls, "base"))
+		_ = base.WithResponseMiddleware(tag(&calls, "derived"))
+
+		resp, err := base.Build().Get(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Body.Close()).To(Succeed())
+		Expect(calls).To(Equal([]string{"base"}))
+	})
+})
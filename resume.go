@@ -0,0 +1,123 @@
+package go_http_client
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// MaxDownloadResumes is how many times a resumable download is continued
+// with a Range request before a read failure is returned to the caller.
+var MaxDownloadResumes = 3
+
+type resumableDownloadTransport struct {
+	wrapped http.RoundTripper
+}
+
+func newResumableDownloadTransport(wrapped http.RoundTripper) http.RoundTripper {
+	return &resumableDownloadTransport{wrapped: wrapped}
+}
+
+func (t resumableDownloadTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.wrapped.RoundTrip(req)
+	if err != nil || !resumable(req, resp) {
+		return resp, err
+	}
+
+	validator := resp.Header.Get("ETag")
+	if validator == "" {
+		validator = resp.Header.Get("Last-Modified")
+	}
+
+	resp.Body = &resumableBody{
+		wrapped:   t.wrapped,
+		req:       req,
+		body:      resp.Body,
+		validator: validator,
+	}
+	return resp, nil
+}
+
+// resumable reports whether the response to req can be continued with a
+// Range request: a complete GET response that the server advertised range
+// support for, with a body the transport has not decompressed, as its byte
+// offsets would not match the server's.
+func resumable(req *http.Request, resp *http.Response) bool {
+	return req.Method == http.MethodGet &&
+		req.Header.Get("Range") == "" &&
+		resp.StatusCode == http.StatusOK &&
+		!resp.Uncompressed &&
+		resp.Header.Get("Content-Encoding") == "" &&
+		strings.EqualFold(resp.Header.Get("Accept-Ranges"), "bytes")
+}
+
+// resumableBody continues the body with a Range request from the last
+// byte received when reading it fails part way. The request carries
+// If-Range, so a resource that changed is not stitched together.
+type resumableBody struct {
+	wrapped   http.RoundTripper
+	req       *http.Request
+	body      io.ReadCloser
+	validator string
+	received  int64
+	resumes   int
+	err       error
+}
+
+func (b *resumableBody) Read(p []byte) (int, error) {
+	for {
+		if b.err != nil {
+			return 0, b.err
+		}
+
+		n, err := b.body.Read(p)
+		b.received += int64(n)
+
+		if err == nil || errors.Is(err, io.EOF) || b.req.Context().Err() != nil || b.resumes >= MaxDownloadResumes {
+			return n, err
+		}
+
+		if resumeErr := b.resume(); resumeErr != nil {
+			b.err = fmt.Errorf("%w (failed to resume download: %w)", err, resumeErr)
+			return n, b.err
+		}
+
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+func (b *resumableBody) resume() error {
+	b.resumes++
+	_ = b.body.Close()
+
+	req := b.req.Clone(b.req.Context())
+	req.Header.Set("Range", "bytes="+strconv.FormatInt(b.received, 10)+"-")
+	if b.validator != "" {
+		req.Header.Set("If-Range", b.validator)
+	}
+
+	resp, err := b.wrapped.RoundTrip(req)
+	if err != nil {
+		b.body = http.NoBody
+		return err
+	}
+
+	if resp.StatusCode != http.StatusPartialContent ||
+		!strings.HasPrefix(resp.Header.Get("Content-Range"), "bytes "+strconv.FormatInt(b.received, 10)+"-") {
+		_ = resp.Body.Close()
+		b.body = http.NoBody
+		return &StatusError{StatusCode: resp.StatusCode}
+	}
+
+	b.body = resp.Body
+	return nil
+}
+
+func (b *resumableBody) Close() error {
+	return b.body.Close()
+}
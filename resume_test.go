@@ -0,0 +1,92 @@
+package go_http_client_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Resumable Downloads", func() {
+	content := bytes.Repeat([]byte("0123456789"), 10<<10)
+
+	var (
+		mu     sync.Mutex
+		ranges []string
+		etag   string
+	)
+
+	// serve fails the first full download half way through, and serves
+	// range requests with http.ServeContent
+	serve := func(acceptRanges bool) *httptest.Server {
+		ranges = nil
+		etag = `"v1"`
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			ranges = append(ranges, r.Header.Get("Range"))
+			mu.Unlock()
+
+			w.Header().Set("ETag", etag)
+			if r.Header.Get("Range") != "" {
+				http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+				return
+			}
+
+			if acceptRanges {
+				w.Header().Set("Accept-Ranges", "bytes")
+			}
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			_, _ = w.Write(content[:len(content)/2])
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}))
+	}
+
+	It("resumes a failed download with a range request", func() {
+		server := serve(true)
+		defer server.Close()
+
+		resp, err := baseBuilder().WithResumableDownloads().Build().Get(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).ToNot(HaveOccurred(), "download not resumed")
+		Expect(body).To(Equal(content), "resumed body not stitched correctly")
+
+		Expect(ranges).To(HaveLen(2))
+		Expect(ranges[1]).To(MatchRegexp(`^bytes=\d+-$`), "resume did not request the remaining bytes")
+	})
+
+	It("does not stitch a resource that changed", func() {
+		server := serve(true)
+		defer server.Close()
+
+		resp, err := baseBuilder().WithResumableDownloads().Build().Get(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		defer resp.Body.Close()
+
+		etag = `"v2"`
+		_, err = io.ReadAll(resp.Body)
+		Expect(err).To(MatchError(ContainSubstring("failed to resume download")), "changed resource stitched")
+	})
+
+	It("returns the read error from servers without range support", func() {
+		server := serve(false)
+		defer server.Close()
+
+		resp, err := baseBuilder().WithResumableDownloads().Build().Get(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		defer resp.Body.Close()
+
+		_, err = io.ReadAll(resp.Body)
+		Expect(err).To(HaveOccurred(), "truncated body read successfully")
+		Expect(ranges).To(HaveLen(1), "resumed without range support")
+	})
+})
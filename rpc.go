@@ -0,0 +1,84 @@
+package go_http_client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+)
+
+// RPCError is the error member of a JSON-RPC 2.0 response, returned by
+// CallRPC when the server reports that the call failed.
+type RPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+type rpcRequest struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+	ID      uint64 `json:"id"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *RPCError       `json:"error"`
+}
+
+var rpcID atomic.Uint64
+
+// CallRPC calls method on the JSON-RPC 2.0 endpoint at url with params,
+// decoding the result into result unless it is nil. An error response is
+// returned as an *RPCError, even when served with a non-2XX status.
+func CallRPC(ctx context.Context, client *http.Client, url, method string, params, result any) error {
+	body, err := json.Marshal(rpcRequest{
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  params,
+		ID:      rpcID.Add(1),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode rpc request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var out rpcResponse
+	resp, err := DoJSON(client, req, &out)
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && resp != nil && json.Unmarshal(resp.Body, &out) == nil && out.Error != nil {
+		return out.Error
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if out.Error != nil {
+		return out.Error
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	if err := json.Unmarshal(out.Result, result); err != nil {
+		return fmt.Errorf("failed to decode rpc result: %w", err)
+	}
+
+	return nil
+}
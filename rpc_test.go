@@ -0,0 +1,78 @@
+package go_http_client_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	httpclient "github.com/JSainsburyPLC/danielchurm/go-http-client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CallRPC", func() {
+	type rpcRequest struct {
+		JSONRPC string          `json:"jsonrpc"`
+		Method  string          `json:"method"`
+		Params  json.RawMessage `json:"params"`
+		ID      uint64          `json:"id"`
+	}
+
+	var (
+		server   *httptest.Server
+		received rpcRequest
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(json.NewDecoder(r.Body).Decode(&received)).To(Succeed())
+
+			w.Header().Set("Content-Type", "application/json")
+			switch received.Method {
+			case "add":
+				var params []int
+				Expect(json.Unmarshal(received.Params, &params)).To(Succeed())
+				_ = json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "result": params[0] + params[1], "id": received.ID})
+			case "fail":
+				w.WriteHeader(http.StatusInternalServerError)
+				_, _ = w.Write([]byte(`{"jsonrpc":"2.0","error":{"code":-32000,"message":"backend down"},"id":1}`))
+			default:
+				_, _ = w.Write([]byte(`{"jsonrpc":"2.0","error":{"code":-32601,"message":"method not found"},"id":1}`))
+			}
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("sends the envelope and decodes the result", func() {
+		var sum int
+		err := httpclient.CallRPC(context.Background(), baseBuilder().Build(), server.URL, "add", []int{2, 3}, &sum)
+		Expect(err).ToNot(HaveOccurred(), "call failed")
+		Expect(sum).To(Equal(5), "result not decoded")
+
+		Expect(received.JSONRPC).To(Equal("2.0"))
+		Expect(received.Method).To(Equal("add"))
+		Expect(received.ID).ToNot(BeZero(), "request sent without an id")
+	})
+
+	It("returns an RPC error response as an RPCError", func() {
+		err := httpclient.CallRPC(context.Background(), baseBuilder().Build(), server.URL, "missing", nil, nil)
+
+		var rpcErr *httpclient.RPCError
+		Expect(errors.As(err, &rpcErr)).To(BeTrue(), "error is not an RPCError")
+		Expect(rpcErr.Code).To(Equal(-32601))
+		Expect(rpcErr.Message).To(Equal("method not found"))
+	})
+
+	It("returns an RPC error served with a non-2XX status as an RPCError", func() {
+		err := httpclient.CallRPC(context.Background(), baseBuilder().Build(), server.URL, "fail", nil, nil)
+
+		var rpcErr *httpclient.RPCError
+		Expect(errors.As(err, &rpcErr)).To(BeTrue(), "error is not an RPCError")
+		Expect(rpcErr.Code).To(Equal(-32000))
+	})
+})
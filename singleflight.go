@@ -0,0 +1,68 @@
+package go_http_client
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+
+	"golang.org/x/sync/singleflight"
+)
+
+type singleFlightTransport struct {
+	wrapped http.RoundTripper
+	group   *singleflight.Group
+}
+
+func newSingleFlightTransport(wrapped http.RoundTripper) http.RoundTripper {
+	return &singleFlightTransport{
+		wrapped: wrapped,
+		group:   &singleflight.Group{},
+	}
+}
+
+type sharedResponse struct {
+	resp *http.Response
+	body []byte
+}
+
+// RoundTrip sends one request for each set of identical GET or HEAD requests
+// in flight together, buffering the response so that every caller gets its
+// own copy. A caller whose context ends stops waiting, but the request is
+// sent with the context of the caller that started it.
+func (t singleFlightTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return t.wrapped.RoundTrip(req)
+	}
+
+	result := t.group.DoChan(req.Method+" "+req.URL.String(), func() (any, error) {
+		resp, err := t.wrapped.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read shared response body: %w", err)
+		}
+
+		return sharedResponse{resp: resp, body: body}, nil
+	})
+
+	select {
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	case r := <-result:
+		if r.Err != nil {
+			return nil, r.Err
+		}
+
+		shared := r.Val.(sharedResponse)
+		resp := *shared.resp
+		resp.Header = shared.resp.Header.Clone()
+		resp.Body = io.NopCloser(bytes.NewReader(shared.body))
+		resp.Request = req
+		return &resp, nil
+	}
+}
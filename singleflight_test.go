@@ -0,0 +1,83 @@
+package go_http_client_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Single Flight", func() {
+	var (
+		server  *httptest.Server
+		hits    atomic.Int32
+		release chan struct{}
+	)
+
+	BeforeEach(func() {
+		hits.Store(0)
+		release = make(chan struct{})
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits.Add(1)
+			<-release
+			_, _ = w.Write([]byte("shared"))
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("coalesces concurrent identical GETs into one upstream call", func() {
+		client := baseBuilder().WithSingleFlight().Build()
+
+		var (
+			wg     sync.WaitGroup
+			bodies = make([]string, 100)
+			errs   = make([]error, 100)
+		)
+		for i := range bodies {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp, err := client.Get(server.URL + "/item")
+				if err != nil {
+					errs[i] = err
+					return
+				}
+				defer resp.Body.Close()
+
+				body, err := io.ReadAll(resp.Body)
+				bodies[i], errs[i] = string(body), err
+			}()
+		}
+
+		Eventually(hits.Load).Should(BeEquivalentTo(1))
+		Consistently(hits.Load, "50ms").Should(BeEquivalentTo(1), "identical requests reached the upstream")
+		close(release)
+		wg.Wait()
+
+		for i := range bodies {
+			Expect(errs[i]).ToNot(HaveOccurred())
+			Expect(bodies[i]).To(Equal("shared"), "caller did not get its own copy of the body")
+		}
+	})
+
+	It("does not coalesce other methods", func() {
+		close(release)
+		client := baseBuilder().WithSingleFlight().Build()
+
+		for range 2 {
+			resp, err := client.Post(server.URL, "text/plain", strings.NewReader("body"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.Body.Close()).To(Succeed())
+		}
+
+		Expect(hits.Load()).To(BeEquivalentTo(2))
+	})
+})
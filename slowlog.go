@@ -0,0 +1,52 @@
+package go_http_client
+
+import (
+	"net/http"
+	"time"
+
+	log "github.com/JSainsburyPLC/go-logrus-wrapper/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// logSlowRequest logs a request that took longer than the slow request
+// threshold, with a status code of 0 if it failed without a response.
+var logSlowRequest = func(req *http.Request, statusCode int, duration time.Duration, err error) {
+	fields := logrus.Fields{
+		"method":      req.Method,
+		"url":         req.URL.Redacted(),
+		"status_code": statusCode,
+		"duration_ms": duration.Milliseconds(),
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+	}
+
+	log.WithFields(fields).Warn("slow request")
+}
+
+type slowRequestLogTransport struct {
+	wrapped   http.RoundTripper
+	threshold time.Duration
+}
+
+func newSlowRequestLogTransport(wrapped http.RoundTripper, threshold time.Duration) http.RoundTripper {
+	return &slowRequestLogTransport{
+		wrapped:   wrapped,
+		threshold: threshold,
+	}
+}
+
+func (t slowRequestLogTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.wrapped.RoundTrip(req)
+
+	if duration := time.Since(start); duration > t.threshold {
+		var statusCode int
+		if resp != nil {
+			statusCode = resp.StatusCode
+		}
+		logSlowRequest(req, statusCode, duration, err)
+	}
+
+	return resp, err
+}
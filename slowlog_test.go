@@ -0,0 +1,72 @@
+package go_http_client_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	httpclient "github.com/JSainsburyPLC/danielchurm/go-http-client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Slow request log", func() {
+	type logged struct {
+		method     string
+		url        string
+		statusCode int
+		duration   time.Duration
+	}
+
+	var (
+		server *httptest.Server
+		logs   []logged
+	)
+
+	BeforeEach(func() {
+		logs = nil
+		DeferCleanup(httpclient.SetSlowRequestLogger(func(req *http.Request, statusCode int, duration time.Duration, err error) {
+			logs = append(logs, logged{req.Method, req.URL.String(), statusCode, duration})
+		}))
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/slow" {
+				time.Sleep(100 * time.Millisecond)
+				w.WriteHeader(http.StatusAccepted)
+			}
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("logs requests over the threshold", func() {
+		client := baseBuilder().WithSlowRequestLog(50 * time.Millisecond).Build()
+
+		resp, err := client.Get(server.URL + "/slow")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Body.Close()).To(Succeed())
+
+		Expect(logs).To(HaveLen(1), "slow request not logged")
+		Expect(logs[0].method).To(Equal(http.MethodGet))
+		Expect(logs[0].url).To(Equal(server.URL + "/slow"))
+		Expect(logs[0].statusCode).To(Equal(http.StatusAccepted))
+		Expect(logs[0].duration).To(BeNumerically(">=", 100*time.Millisecond))
+	})
+
+	It("does not log requests within the threshold", func() {
+		client := baseBuilder().WithSlowRequestLog(50 * time.Millisecond).Build()
+
+		resp, err := client.Get(server.URL + "/fast")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Body.Close()).To(Succeed())
+
+		Expect(logs).To(BeEmpty(), "fast request logged")
+	})
+
+	It("times the whole transport chain", func() {
+		chain := baseBuilder().WithSlowRequestLog(time.Second).WithStats(&httpclient.Stats{}).TransportChain()
+		Expect(chain[0]).To(Equal("slow-request-log"))
+	})
+})
@@ -0,0 +1,54 @@
+package go_http_client
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// sniffLen is how much of the body http.DetectContentType considers.
+const sniffLen = 512
+
+type contentTypeSniffingTransport struct {
+	wrapped http.RoundTripper
+}
+
+func newContentTypeSniffingTransport(wrapped http.RoundTripper) http.RoundTripper {
+	return &contentTypeSniffingTransport{wrapped: wrapped}
+}
+
+// RoundTrip peeks at the start of a body sent without a Content-Type,
+// sending the peeked bytes followed by the rest of the body unchanged.
+func (t contentTypeSniffingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body == nil || req.Body == http.NoBody || req.Header.Get("Content-Type") != "" {
+		return t.wrapped.RoundTrip(req)
+	}
+
+	peeked := make([]byte, sniffLen)
+	n, err := io.ReadFull(req.Body, peeked)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		_ = req.Body.Close()
+		return nil, fmt.Errorf("failed to sniff request body: %w", err)
+	}
+	peeked = peeked[:n]
+
+	body := req.Body
+	req = req.Clone(req.Context())
+	req.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(peeked), body), body}
+	req.Header.Set("Content-Type", sniffContentType(peeked))
+
+	return t.wrapped.RoundTrip(req)
+}
+
+func sniffContentType(peeked []byte) string {
+	trimmed := bytes.TrimLeft(peeked, " \t\r\n")
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		return "application/json"
+	}
+
+	return http.DetectContentType(peeked)
+}
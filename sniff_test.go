@@ -0,0 +1,67 @@
+package go_http_client_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Content Type Sniffing", func() {
+	var (
+		server      *httptest.Server
+		contentType string
+		received    []byte
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			contentType = r.Header.Get("Content-Type")
+			received, _ = io.ReadAll(r.Body)
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	post := func(body io.Reader, contentType string) {
+		req, err := http.NewRequest(http.MethodPost, server.URL, body)
+		Expect(err).ToNot(HaveOccurred())
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+
+		resp, err := baseBuilder().WithContentTypeSniffing().Build().Do(req)
+		Expect(err).ToNot(HaveOccurred(), "request failed")
+		Expect(resp.Body.Close()).To(Succeed())
+	}
+
+	It("detects JSON bodies", func() {
+		post(strings.NewReader(`  [{"sku":"123"}]`), "")
+		Expect(contentType).To(Equal("application/json"))
+		Expect(string(received)).To(Equal(`  [{"sku":"123"}]`), "body not restored")
+	})
+
+	It("detects binary bodies and restores bodies longer than the sniffed prefix", func() {
+		body := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 2048)...)
+		post(bytes.NewReader(body), "")
+		Expect(contentType).To(Equal("image/png"))
+		Expect(received).To(Equal(body), "body not restored")
+	})
+
+	It("leaves an explicit Content-Type alone", func() {
+		post(strings.NewReader("sku=123&qty=1"), "application/x-www-form-urlencoded")
+		Expect(contentType).To(Equal("application/x-www-form-urlencoded"))
+		Expect(string(received)).To(Equal("sku=123&qty=1"))
+	})
+
+	It("falls back to the detected text type for unrecognised bodies", func() {
+		post(strings.NewReader("sku=123&qty=1"), "")
+		Expect(contentType).To(Equal("text/plain; charset=utf-8"))
+	})
+})
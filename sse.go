@@ -0,0 +1,218 @@
+package go_http_client
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JSainsburyPLC/danielchurm/go-http-client/circuitbreaker"
+)
+
+var (
+	// SSEReconnectBackoff is how long Subscribe waits before its nth
+	// consecutive attempt to reconnect, unless the server set a retry
+	// delay on the stream.
+	SSEReconnectBackoff = circuitbreaker.ExponentialBackoff(time.Second, 30*time.Second)
+
+	// SSEMaxReconnects is how many consecutive failed reconnection attempts
+	// Subscribe makes before giving up on a stream.
+	SSEMaxReconnects = 5
+)
+
+// ErrClientTimeout is returned by Subscribe for a client with a Timeout,
+// which would end every event stream once it elapsed.
+var ErrClientTimeout = errors.New("client timeout would end the event stream")
+
+// SSEEvent is a server-sent event. Event is "message" unless the server
+// named the event type.
+type SSEEvent struct {
+	ID    string
+	Event string
+	Data  string
+}
+
+// Subscribe connects to the server-sent event stream at url and delivers its
+// events on the returned channel. When the stream ends or the connection
+// drops, Subscribe reconnects with SSEReconnectBackoff, sending the ID of the
+// last event received as Last-Event-ID. The channel is closed once ctx is
+// done, the server answers a reconnection with anything but a 200 event
+// stream, or SSEMaxReconnects consecutive reconnections fail.
+//
+// The initial connection is made before Subscribe returns, so a failure to
+// connect is returned directly. The client must be built with WithTimeout(0),
+// leaving ctx and ResponseBodyTimeout to bound the stream, as a client timeout
+// would cut off every stream once it elapsed; Subscribe returns
+// ErrClientTimeout otherwise.
+func Subscribe(ctx context.Context, client *http.Client, url string) (<-chan SSEEvent, error) {
+	if client.Timeout > 0 {
+		return nil, ErrClientTimeout
+	}
+
+	s := &sseStream{
+		client: client,
+		url:    url,
+		events: make(chan SSEEvent),
+	}
+
+	resp, err := s.connect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	go s.run(ctx, resp)
+
+	return s.events, nil
+}
+
+type sseStream struct {
+	client      *http.Client
+	url         string
+	events      chan SSEEvent
+	lastEventID string
+	retry       time.Duration
+}
+
+func (s *sseStream) connect(ctx context.Context) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+	if s.lastEventID != "" {
+		req.Header.Set("Last-Event-ID", s.lastEventID)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, &StatusError{StatusCode: resp.StatusCode}
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType != "text/event-stream" {
+		_ = resp.Body.Close()
+		return nil, &ContentTypeError{ContentType: contentType}
+	}
+
+	return resp, nil
+}
+
+func (s *sseStream) run(ctx context.Context, resp *http.Response) {
+	defer close(s.events)
+
+	for {
+		s.read(ctx, resp.Body)
+		_ = resp.Body.Close()
+
+		var ok bool
+		if resp, ok = s.reconnect(ctx); !ok {
+			return
+		}
+	}
+}
+
+func (s *sseStream) reconnect(ctx context.Context) (*http.Response, bool) {
+	for attempt := 1; attempt <= SSEMaxReconnects; attempt++ {
+		delay := s.retry
+		if delay == 0 {
+			delay = SSEReconnectBackoff(attempt)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, false
+		case <-timer.C:
+		}
+
+		resp, err := s.connect(ctx)
+		if err == nil {
+			return resp, true
+		}
+
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) || errors.Is(err, ErrUnexpectedContentType) || ctx.Err() != nil {
+			return nil, false
+		}
+	}
+
+	return nil, false
+}
+
+// read parses the stream following the HTML server-sent events format,
+// delivering each complete event until the stream ends or ctx is done.
+func (s *sseStream) read(ctx context.Context, body io.Reader) {
+	reader := bufio.NewReader(body)
+
+	var (
+		event SSEEvent
+		data  strings.Builder
+	)
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			// an event is only dispatched once the blank line ending it
+			// arrives, so a partial event at the end is dropped
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		if line == "" {
+			if data.Len() > 0 {
+				event.ID = s.lastEventID
+				event.Data = strings.TrimSuffix(data.String(), "\n")
+				if event.Event == "" {
+					event.Event = "message"
+				}
+
+				select {
+				case s.events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			event = SSEEvent{}
+			data.Reset()
+			continue
+		}
+
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "event":
+			event.Event = value
+		case "data":
+			data.WriteString(value)
+			data.WriteByte('\n')
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				s.lastEventID = value
+			}
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil && ms >= 0 {
+				s.retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+}
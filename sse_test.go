@@ -0,0 +1,126 @@
+package go_http_client_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	httpclient "github.com/JSainsburyPLC/danielchurm/go-http-client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Subscribe", func() {
+	stream := func(w http.ResponseWriter, lines ...string) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, line := range lines {
+			_, _ = fmt.Fprint(w, line)
+			w.(http.Flusher).Flush()
+		}
+	}
+
+	collect := func(events <-chan httpclient.SSEEvent) []httpclient.SSEEvent {
+		var collected []httpclient.SSEEvent
+		for event := range events {
+			collected = append(collected, event)
+		}
+		return collected
+	}
+
+	It("parses events from the stream", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Last-Event-ID") != "" {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			Expect(r.Header.Get("Accept")).To(Equal("text/event-stream"))
+			stream(w,
+				"retry: 1\n",
+				": keep-alive\n\n",
+				"id: 1\ndata: first\n\n",
+				"event: update\nid: 2\ndata: line one\ndata: line two\n\n",
+				"data: no id\r\n\r\n",
+			)
+		}))
+		defer server.Close()
+
+		events, err := httpclient.Subscribe(context.Background(), baseBuilder().WithTimeout(0).Build(), server.URL)
+		Expect(err).ToNot(HaveOccurred(), "subscribe failed")
+
+		Expect(collect(events)).To(Equal([]httpclient.SSEEvent{
+			{ID: "1", Event: "message", Data: "first"},
+			{ID: "2", Event: "update", Data: "line one\nline two"},
+			{ID: "2", Event: "message", Data: "no id"},
+		}))
+	})
+
+	It("reconnects with the last event ID", func() {
+		var (
+			mu           sync.Mutex
+			lastEventIDs []string
+		)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			lastEventIDs = append(lastEventIDs, r.Header.Get("Last-Event-ID"))
+			mu.Unlock()
+
+			switch r.Header.Get("Last-Event-ID") {
+			case "":
+				stream(w, "retry: 1\n", "id: 1\ndata: first\n\n")
+			case "1":
+				stream(w, "id: 2\ndata: second\n\n")
+			default:
+				w.WriteHeader(http.StatusNoContent)
+			}
+		}))
+		defer server.Close()
+
+		events, err := httpclient.Subscribe(context.Background(), baseBuilder().WithTimeout(0).Build(), server.URL)
+		Expect(err).ToNot(HaveOccurred(), "subscribe failed")
+
+		Expect(collect(events)).To(Equal([]httpclient.SSEEvent{
+			{ID: "1", Event: "message", Data: "first"},
+			{ID: "2", Event: "message", Data: "second"},
+		}))
+		Expect(lastEventIDs).To(Equal([]string{"", "1", "2"}), "last event ID not sent on reconnect")
+	})
+
+	It("closes the channel when the context is cancelled", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			stream(w, "data: first\n\n")
+			<-r.Context().Done()
+		}))
+		defer server.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		events, err := httpclient.Subscribe(ctx, baseBuilder().WithTimeout(0).Build(), server.URL)
+		Expect(err).ToNot(HaveOccurred(), "subscribe failed")
+
+		Eventually(events).Should(Receive(Equal(httpclient.SSEEvent{Event: "message", Data: "first"})))
+		cancel()
+		Eventually(events).Should(BeClosed(), "channel not closed after cancel")
+	})
+
+	It("refuses clients with a timeout", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			stream(w, "data: first\n\n")
+		}))
+		defer server.Close()
+
+		_, err := httpclient.Subscribe(context.Background(), baseBuilder().Build(), server.URL)
+		Expect(err).To(MatchError(httpclient.ErrClientTimeout))
+	})
+
+	It("returns an error when the stream cannot be opened", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		_, err := httpclient.Subscribe(context.Background(), baseBuilder().WithTimeout(0).Build(), server.URL)
+		Expect(err).To(MatchError(&httpclient.StatusError{StatusCode: http.StatusUnauthorized}))
+	})
+})
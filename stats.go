@@ -0,0 +1,129 @@
+package go_http_client
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/sony/gobreaker/v2"
+)
+
+// ClientStats is a point in time view of the counters kept by Stats.
+type ClientStats struct {
+	Requests          int64
+	Errors            int64
+	BreakerRejections int64
+	BreakerTrips      int64
+	OpenBreakers      int64
+
+	// RequestBytes and ResponseBytes count the body bytes sent and read, as
+	// they stream, so a response body counts only as far as it was read.
+	RequestBytes  int64
+	ResponseBytes int64
+}
+
+// Stats keeps lightweight counters for the clients built with it, for a
+// health view without a metrics backend. The zero value is ready to use and
+// can be shared by several clients to aggregate them.
+type Stats struct {
+	requests          atomic.Int64
+	errors            atomic.Int64
+	breakerRejections atomic.Int64
+	breakerTrips      atomic.Int64
+	openBreakers      atomic.Int64
+	requestBytes      atomic.Int64
+	responseBytes     atomic.Int64
+}
+
+func (s *Stats) Snapshot() ClientStats {
+	return ClientStats{
+		Requests:          s.requests.Load(),
+		Errors:            s.errors.Load(),
+		BreakerRejections: s.breakerRejections.Load(),
+		BreakerTrips:      s.breakerTrips.Load(),
+		OpenBreakers:      s.openBreakers.Load(),
+		RequestBytes:      s.requestBytes.Load(),
+		ResponseBytes:     s.responseBytes.Load(),
+	}
+}
+
+// observeStateChange returns an OnStateChange that counts trips and open
+// breakers before calling onStateChange, if any. Half-open breakers are not
+// counted as open.
+func (s *Stats) observeStateChange(onStateChange func(string, gobreaker.State, gobreaker.State)) func(string, gobreaker.State, gobreaker.State) {
+	return func(name string, from gobreaker.State, to gobreaker.State) {
+		if from == gobreaker.StateOpen {
+			s.openBreakers.Add(-1)
+		}
+
+		if to == gobreaker.StateOpen {
+			s.breakerTrips.Add(1)
+			s.openBreakers.Add(1)
+		}
+
+		if onStateChange != nil {
+			onStateChange(name, from, to)
+		}
+	}
+}
+
+type statsTransport struct {
+	wrapped http.RoundTripper
+	stats   *Stats
+}
+
+func newStatsTransport(wrapped http.RoundTripper, stats *Stats) http.RoundTripper {
+	return &statsTransport{
+		wrapped: wrapped,
+		stats:   stats,
+	}
+}
+
+func (t statsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.stats.requests.Add(1)
+
+	if req.Body != nil && req.Body != http.NoBody {
+		req = req.Clone(req.Context())
+		req.Body = &countingBody{ReadCloser: req.Body, count: &t.stats.requestBytes}
+
+		if getBody := req.GetBody; getBody != nil {
+			req.GetBody = func() (io.ReadCloser, error) {
+				body, err := getBody()
+				if err != nil || body == http.NoBody {
+					return body, err
+				}
+				return &countingBody{ReadCloser: body, count: &t.stats.requestBytes}, nil
+			}
+		}
+	}
+
+	resp, err := t.wrapped.RoundTrip(req)
+	if err != nil {
+		t.stats.errors.Add(1)
+	}
+
+	// A 101 response body is also the connection's writer, so it is left
+	// unwrapped.
+	if resp != nil && resp.StatusCode != http.StatusSwitchingProtocols {
+		resp.Body = &countingBody{ReadCloser: resp.Body, count: &t.stats.responseBytes}
+	}
+
+	if errors.Is(err, gobreaker.ErrOpenState) || errors.Is(err, gobreaker.ErrTooManyRequests) {
+		t.stats.breakerRejections.Add(1)
+	}
+
+	return resp, err
+}
+
+// countingBody adds the bytes read through it to count.
+type countingBody struct {
+	io.ReadCloser
+	count *atomic.Int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.count.Add(int64(n))
+	return n, err
+}
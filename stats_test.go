@@ -0,0 +1,122 @@
+package go_http_client_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	httpclient "github.com/JSainsburyPLC/danielchurm/go-http-client"
+	"github.com/JSainsburyPLC/danielchurm/go-http-client/circuitbreaker"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sony/gobreaker/v2"
+)
+
+var _ = Describe("Stats", func() {
+	var server *httptest.Server
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/fail" {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+
+			if r.URL.Path == "/upload" {
+				_, _ = io.Copy(io.Discard, r.Body)
+				_, _ = w.Write(bytes.Repeat([]byte("r"), 2500))
+			}
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	get := func(client *http.Client, path string) error {
+		resp, err := client.Get(server.URL + path)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}
+
+	It("counts requests, errors and breaker activity", func() {
+		stats := &httpclient.Stats{}
+		client := baseBuilder().
+			WithCircuitBreakerSettings(circuitbreaker.Settings{
+				Settings: gobreaker.Settings{
+					ReadyToTrip: func(gobreaker.Counts) bool { return true },
+					Timeout:     10 * time.Millisecond,
+				},
+			}).
+			WithStats(stats).
+			Build()
+
+		Expect(get(client, "/")).To(Succeed())
+		Expect(get(client, "/fail")).To(Succeed(), "server error surfaced as an error")
+		Expect(get(client, "/")).ToNot(Succeed(), "breaker did not reject the request")
+
+		Expect(stats.Snapshot()).To(Equal(httpclient.ClientStats{
+			Requests:          3,
+			Errors:            1,
+			BreakerRejections: 1,
+			BreakerTrips:      1,
+			OpenBreakers:      1,
+		}))
+
+		time.Sleep(20 * time.Millisecond)
+		Expect(get(client, "/")).To(Succeed(), "half-open probe failed")
+		Expect(stats.Snapshot().OpenBreakers).To(BeZero(), "closed breaker still counted as open")
+		Expect(stats.Snapshot().Requests).To(BeEquivalentTo(4))
+	})
+
+	It("aggregates the clients sharing it", func() {
+		stats := &httpclient.Stats{}
+		first := baseBuilder().WithStats(stats).Build()
+		second := baseBuilder().WithStats(stats).Build()
+
+		Expect(get(first, "/")).To(Succeed())
+		Expect(get(second, "/")).To(Succeed())
+		Expect(stats.Snapshot().Requests).To(BeEquivalentTo(2))
+	})
+
+	It("counts request and response body bytes", func() {
+		stats := &httpclient.Stats{}
+		client := baseBuilder().WithStats(stats).Build()
+
+		resp, err := client.Post(server.URL+"/upload", "text/plain", bytes.NewReader(bytes.Repeat([]byte("q"), 1000)))
+		Expect(err).ToNot(HaveOccurred())
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Body.Close()).To(Succeed())
+		Expect(body).To(HaveLen(2500))
+
+		snapshot := stats.Snapshot()
+		Expect(snapshot.RequestBytes).To(BeEquivalentTo(1000), "request bytes miscounted")
+		Expect(snapshot.ResponseBytes).To(BeEquivalentTo(2500), "response bytes miscounted")
+	})
+
+	It("counts streamed bodies as they are read", func() {
+		stats := &httpclient.Stats{}
+		client := baseBuilder().WithStats(stats).Build()
+
+		reader, writer := io.Pipe()
+		go func() {
+			for range 4 {
+				_, _ = writer.Write(bytes.Repeat([]byte("s"), 256))
+			}
+			_ = writer.Close()
+		}()
+
+		resp, err := client.Post(server.URL+"/upload", "text/plain", reader)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stats.Snapshot().RequestBytes).To(BeEquivalentTo(1024), "streamed request bytes miscounted")
+
+		_, err = io.ReadFull(resp.Body, make([]byte, 100))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stats.Snapshot().ResponseBytes).To(BeEquivalentTo(100), "response bytes counted before being read")
+		Expect(resp.Body.Close()).To(Succeed())
+	})
+})
@@ -0,0 +1,70 @@
+package go_http_client
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// TLSVersionError is returned when a handshake fails because the server
+// cannot negotiate a TLS version at or above the client's minimum.
+type TLSVersionError struct {
+	MinVersion uint16
+	Err        error
+}
+
+func (e *TLSVersionError) Error() string {
+	return fmt.Sprintf("server does not support %s or later, the minimum this client allows: %v",
+		tls.VersionName(e.MinVersion), e.Err)
+}
+
+func (e *TLSVersionError) Unwrap() error {
+	return e.Err
+}
+
+// tlsVersionErrors are the messages crypto/tls fails a handshake with when
+// the server rejects every offered version or picks one the client refuses.
+// The errors themselves are unexported, so they can only be told apart by
+// their text.
+var tlsVersionErrors = []string{
+	"protocol version not supported",
+	"unsupported protocol version",
+}
+
+type minTLSVersionTransport struct {
+	wrapped    http.RoundTripper
+	minVersion uint16
+}
+
+func newMinTLSVersionTransport(wrapped http.RoundTripper, minVersion uint16) http.RoundTripper {
+	return &minTLSVersionTransport{
+		wrapped:    wrapped,
+		minVersion: minVersion,
+	}
+}
+
+func (t minTLSVersionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.wrapped.RoundTrip(req)
+	if err != nil && isTLSVersionError(err) {
+		return nil, &TLSVersionError{MinVersion: t.minVersion, Err: err}
+	}
+
+	return resp, err
+}
+
+func (t minTLSVersionTransport) CloseIdleConnections() {
+	if idler, ok := t.wrapped.(closeIdler); ok {
+		idler.CloseIdleConnections()
+	}
+}
+
+func isTLSVersionError(err error) bool {
+	for _, message := range tlsVersionErrors {
+		if strings.Contains(err.Error(), message) {
+			return true
+		}
+	}
+
+	return false
+}
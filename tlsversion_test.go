@@ -0,0 +1,56 @@
+package go_http_client_test
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	httpclient "github.com/JSainsburyPLC/danielchurm/go-http-client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Minimum TLS version", func() {
+	var server *httptest.Server
+
+	BeforeEach(func() {
+		server = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		server.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+		server.StartTLS()
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	trustServer := func(cb httpclient.ClientBuilder) httpclient.ClientBuilder {
+		return cb.WithPoolSettings(httpclient.PoolSettings{
+			TLSClientConfig: server.Client().Transport.(*http.Transport).TLSClientConfig,
+		})
+	}
+
+	It("fails with a clear error against a server below the minimum", func() {
+		client := trustServer(baseBuilder().WithMinTLSVersion(tls.VersionTLS13)).Build()
+
+		_, err := client.Get(server.URL)
+
+		var versionErr *httpclient.TLSVersionError
+		Expect(errors.As(err, &versionErr)).To(BeTrue(), "error is not a TLSVersionError: %v", err)
+		Expect(versionErr.MinVersion).To(BeEquivalentTo(tls.VersionTLS13))
+		Expect(err.Error()).To(ContainSubstring("server does not support TLS 1.3 or later"))
+	})
+
+	It("connects to a server meeting the minimum", func() {
+		client := trustServer(baseBuilder().WithMinTLSVersion(tls.VersionTLS12)).Build()
+
+		resp, err := client.Get(server.URL)
+		Expect(err).ToNot(HaveOccurred(), "request failed")
+		Expect(resp.Body.Close()).To(Succeed())
+	})
+
+	It("sets the minimum on the base transport", func() {
+		transport := httpclient.BaseTransport(baseBuilder().WithMinTLSVersion(tls.VersionTLS13))
+		Expect(transport.TLSClientConfig.MinVersion).To(BeEquivalentTo(tls.VersionTLS13))
+	})
+})
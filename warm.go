@@ -0,0 +1,57 @@
+package go_http_client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// WarmPool opens up to count keep-alive connections to url by sending count
+// concurrent HEAD requests through client, so that the first real requests
+// do not pay for dialing and the TLS handshake. The pool only keeps as many
+// idle connections per host as MaxIdleConnsPerHost allows (2 by default).
+// Any status is accepted; only failures to get a response are returned.
+func WarmPool(ctx context.Context, client *http.Client, url string, count int) error {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+
+	for range count {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if err := warm(ctx, client, url); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("failed to warm pool: %w", err)
+	}
+
+	return nil
+}
+
+func warm(ctx context.Context, client *http.Client, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
@@ -0,0 +1,59 @@
+package go_http_client_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+
+	httpclient "github.com/JSainsburyPLC/danielchurm/go-http-client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WarmPool", func() {
+	It("opens connections that later requests reuse", func() {
+		var (
+			conns   atomic.Int32
+			arrived sync.WaitGroup
+		)
+		arrived.Add(3)
+
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodHead {
+				// hold each warming request until all are in flight, so
+				// each one needs a connection of its own
+				arrived.Done()
+				arrived.Wait()
+			}
+		}))
+		server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+			if state == http.StateNew {
+				conns.Add(1)
+			}
+		}
+		server.Start()
+		defer server.Close()
+
+		client := baseBuilder().WithPoolSettings(httpclient.PoolSettings{MaxIdleConnsPerHost: 3}).Build()
+		Expect(httpclient.WarmPool(context.Background(), client, server.URL, 3)).To(Succeed())
+		Expect(conns.Load()).To(BeEquivalentTo(3), "pool not warmed")
+
+		for range 3 {
+			resp, err := client.Get(server.URL)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.Body.Close()).To(Succeed())
+		}
+		Expect(conns.Load()).To(BeEquivalentTo(3), "warmed connections not reused")
+	})
+
+	It("returns the errors of failed warming requests", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		server.Close()
+
+		err := httpclient.WarmPool(context.Background(), baseBuilder().Build(), server.URL, 2)
+		Expect(err).To(MatchError(ContainSubstring("failed to warm pool")))
+	})
+})